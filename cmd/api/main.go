@@ -2,20 +2,30 @@ package main
 
 import (
 	"anpurnama/summarizer-backend/internal/api"
+	"anpurnama/summarizer-backend/internal/config"
 	"anpurnama/summarizer-backend/internal/database"
 	"anpurnama/summarizer-backend/internal/repository"
+	"anpurnama/summarizer-backend/internal/service"
+	"anpurnama/summarizer-backend/internal/service/embedding"
 	"anpurnama/summarizer-backend/internal/service/extractor"
-	"anpurnama/summarizer-backend/internal/service/openrouter"
+	"anpurnama/summarizer-backend/internal/service/jobs"
+	"anpurnama/summarizer-backend/internal/service/llm"
+	"anpurnama/summarizer-backend/internal/service/semantic"
+	"context"
 	"log"
-	"os"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/mattn/go-sqlite3"
 )
 
 func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
 	// Initialize database connection
-	db, err := database.NewDB("./db/database.sqlite")
+	db, err := database.NewDB(cfg.DatabasePath)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
@@ -24,36 +34,73 @@ func main() {
 	// Initialize repositories
 	historyRepo := repository.NewHistoryRepository(db)
 	styleRepo := repository.NewStyleRepository(db)
+	jobRepo := repository.NewJobRepository(db)
+	usageRepo := repository.NewUsageRepository(db)
+	embeddingRepo := repository.NewEmbeddingRepository(db)
+	domainRuleRepo := repository.NewDomainRuleRepository(db)
 
 	// Initialize services
-	extractor, err := extractor.NewContentExtractor()
+	extractor, err := extractor.NewContentExtractor(cfg.Extractor, domainRuleRepo)
 	if err != nil {
 		log.Fatalf("Failed to create content extractor: %v", err)
 	}
 
-	openrouterClient, err := openrouter.NewClient(styleRepo)
+	providers := make(map[llm.Config]llm.Provider)
+	var targets []llm.Target
+	for _, target := range cfg.LLMTargets {
+		provider, ok := providers[target.Provider]
+		if !ok {
+			provider, err = llm.NewProvider(target.Provider)
+			if err != nil {
+				log.Fatalf("Failed to create LLM provider: %v", err)
+			}
+			providers[target.Provider] = provider
+		}
+		log.Printf("Using %s provider with model %s", provider.Name(), target.Model)
+		targets = append(targets, llm.Target{Provider: provider, Model: target.Model})
+	}
+	llmChain := llm.NewChain(targets, cfg.Breaker, cfg.Pricing)
+
+	summarizer := service.NewLLMSummarizer(styleRepo, llmChain, cfg.ChunkLimits)
+	embedder, err := embedding.New(cfg.Embedding)
 	if err != nil {
-		log.Fatalf("Failed to create OpenRouter client: %v", err)
+		log.Fatalf("Failed to create embedder: %v", err)
 	}
+	semanticIndex := semantic.NewIndex(historyRepo, embeddingRepo, embedder)
 
-	ginMode := os.Getenv("GIN_MODE")
+	pipeline := service.NewPipeline(historyRepo, styleRepo, extractor, summarizer, semanticIndex)
+
+	// Start background job workers
+	jobQueue := jobs.NewQueue(jobRepo, pipeline, cfg.JobWorkers)
+	if err := jobQueue.Start(context.Background()); err != nil {
+		log.Fatalf("Failed to start job queue: %v", err)
+	}
+
+	ginMode := cfg.GinMode
 	if ginMode == "" {
 		ginMode = gin.DebugMode
 	}
 	gin.SetMode(ginMode)
 
 	// Setup router
-	router := api.SetupRouter(historyRepo, styleRepo, extractor, openrouterClient)
-
-	// Get port from environment variable or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
+	handler := api.NewHandler(api.Dependencies{
+		HistoryRepo:      historyRepo,
+		StyleRepo:        styleRepo,
+		JobRepo:          jobRepo,
+		UsageRepo:        usageRepo,
+		DomainRuleRepo:   domainRuleRepo,
+		Pipeline:         pipeline,
+		JobQueue:         jobQueue,
+		LLMChain:         llmChain,
+		SemanticIndex:    semanticIndex,
+		BatchConcurrency: cfg.BatchConcurrency,
+		MaxBodyBytes:     cfg.Extractor.MaxBodyBytes,
+	})
+	router := api.SetupRouter(handler)
 
 	// Start server
-	log.Printf("Server starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
+	log.Printf("Server starting on port %s", cfg.Port)
+	if err := router.Run(":" + cfg.Port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"anpurnama/summarizer-backend/internal/config"
+	"anpurnama/summarizer-backend/internal/database"
+	"anpurnama/summarizer-backend/internal/repository"
+	"anpurnama/summarizer-backend/internal/service/embedding"
+	"anpurnama/summarizer-backend/internal/service/extractor"
+	"anpurnama/summarizer-backend/internal/service/semantic"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+)
+
+type command struct {
+	description string
+	run         func(ctx context.Context, cfg *config.Config, db *database.DB, args []string) error
+}
+
+var commands = map[string]command{
+	"embeddings": {
+		description: "embed history rows that have no vectors for the configured embedding model",
+		run:         backfillEmbeddings,
+	},
+	"languages": {
+		description: "detect the ISO 639-1 language of history rows that have none (-all re-detects every row)",
+		run:         backfillLanguages,
+	},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := database.NewDB(cfg.DatabasePath)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := cmd.run(ctx, cfg, db, os.Args[2:]); err != nil {
+		log.Fatalf("Backfill %s failed: %v", os.Args[1], err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", os.Args[0])
+	for name, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", name, cmd.description)
+	}
+	os.Exit(2)
+}
+
+func backfillEmbeddings(ctx context.Context, cfg *config.Config, db *database.DB, args []string) error {
+	flags := flag.NewFlagSet("embeddings", flag.ExitOnError)
+	batchSize := flags.Int("batch", 32, "number of history rows to embed per request")
+	flags.Parse(args)
+
+	embedder, err := embedding.New(cfg.Embedding)
+	if err != nil {
+		return err
+	}
+
+	index := semantic.NewIndex(
+		repository.NewHistoryRepository(db),
+		repository.NewEmbeddingRepository(db),
+		embedder,
+	)
+
+	log.Printf("Embedding history with %s", embedder.Model())
+	done, err := index.Backfill(ctx, *batchSize, func(done int) {
+		log.Printf("Embedded %d history rows", done)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Backfill complete: %d history rows embedded", done)
+	return nil
+}
+
+func backfillLanguages(ctx context.Context, cfg *config.Config, db *database.DB, args []string) error {
+	flags := flag.NewFlagSet("languages", flag.ExitOnError)
+	batchSize := flags.Int("batch", 100, "number of history rows to read per query")
+	all := flags.Bool("all", false, "re-detect rows that already have a language")
+	flags.Parse(args)
+
+	detector, err := extractor.NewLanguageDetector(cfg.Extractor.Languages)
+	if err != nil {
+		return err
+	}
+	historyRepo := repository.NewHistoryRepository(db)
+
+	updated, undetected := 0, 0
+	lastID := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		histories, err := historyRepo.ListAfter(ctx, lastID, max(*batchSize, 1))
+		if err != nil {
+			return err
+		}
+		if len(histories) == 0 {
+			break
+		}
+		lastID = histories[len(histories)-1].ID
+
+		for _, h := range histories {
+			if h.Language != nil && !*all {
+				continue
+			}
+
+			code, _ := detector.Detect(h.Content)
+			var language *string
+			if code == "" {
+				undetected++
+			} else {
+				language = &code
+			}
+			if (h.Language == nil && language == nil) || (h.Language != nil && language != nil && *h.Language == code) {
+				continue
+			}
+			if err := historyRepo.UpdateLanguage(ctx, h.ID, language); err != nil {
+				return err
+			}
+			updated++
+		}
+		log.Printf("Checked history rows up to id %d: %d updated", lastID, updated)
+	}
+
+	log.Printf("Backfill complete: %d history rows updated, %d without a detectable language", updated, undetected)
+	return nil
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_summarization_jobs_status;
+DROP TABLE IF EXISTS summarization_jobs;
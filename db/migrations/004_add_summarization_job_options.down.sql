@@ -0,0 +1 @@
+ALTER TABLE summarization_jobs DROP COLUMN options;
@@ -0,0 +1 @@
+ALTER TABLE summarization_jobs ADD COLUMN options TEXT;
@@ -0,0 +1 @@
+ALTER TABLE history DROP COLUMN chunk_count;
@@ -0,0 +1 @@
+ALTER TABLE history ADD COLUMN chunk_count INTEGER NOT NULL DEFAULT 1;
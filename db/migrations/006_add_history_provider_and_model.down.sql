@@ -0,0 +1,2 @@
+ALTER TABLE history DROP COLUMN model;
+ALTER TABLE history DROP COLUMN provider;
@@ -0,0 +1,2 @@
+ALTER TABLE history ADD COLUMN provider TEXT;
+ALTER TABLE history ADD COLUMN model TEXT;
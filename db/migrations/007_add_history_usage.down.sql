@@ -0,0 +1,7 @@
+DROP INDEX idx_history_created_at;
+
+ALTER TABLE summarization_jobs DROP COLUMN api_key;
+ALTER TABLE history DROP COLUMN api_key;
+ALTER TABLE history DROP COLUMN cost;
+ALTER TABLE history DROP COLUMN completion_tokens;
+ALTER TABLE history DROP COLUMN prompt_tokens;
@@ -0,0 +1,7 @@
+ALTER TABLE history ADD COLUMN prompt_tokens INTEGER;
+ALTER TABLE history ADD COLUMN completion_tokens INTEGER;
+ALTER TABLE history ADD COLUMN cost REAL;
+ALTER TABLE history ADD COLUMN api_key TEXT;
+ALTER TABLE summarization_jobs ADD COLUMN api_key TEXT;
+
+CREATE INDEX idx_history_created_at ON history(created_at);
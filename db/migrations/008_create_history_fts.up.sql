@@ -0,0 +1,28 @@
+CREATE VIRTUAL TABLE history_fts USING fts5(
+    title,
+    content,
+    summary,
+    content='history',
+    content_rowid='id',
+    tokenize='unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER history_fts_after_insert AFTER INSERT ON history BEGIN
+    INSERT INTO history_fts(rowid, title, content, summary)
+    VALUES (new.id, new.title, new.content, new.summary);
+END;
+
+CREATE TRIGGER history_fts_after_delete AFTER DELETE ON history BEGIN
+    INSERT INTO history_fts(history_fts, rowid, title, content, summary)
+    VALUES ('delete', old.id, old.title, old.content, old.summary);
+END;
+
+CREATE TRIGGER history_fts_after_update AFTER UPDATE OF title, content, summary ON history BEGIN
+    INSERT INTO history_fts(history_fts, rowid, title, content, summary)
+    VALUES ('delete', old.id, old.title, old.content, old.summary);
+    INSERT INTO history_fts(rowid, title, content, summary)
+    VALUES (new.id, new.title, new.content, new.summary);
+END;
+
+-- Index the rows that existed before the table was created
+INSERT INTO history_fts(history_fts) VALUES ('rebuild');
@@ -0,0 +1,12 @@
+CREATE TABLE history_embeddings (
+    history_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    model TEXT NOT NULL,
+    dimensions INTEGER NOT NULL,
+    vector BLOB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (history_id, kind),
+    FOREIGN KEY (history_id) REFERENCES history(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_history_embeddings_model ON history_embeddings(model);
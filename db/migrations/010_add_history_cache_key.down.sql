@@ -0,0 +1,4 @@
+DROP INDEX idx_history_cache_key;
+
+ALTER TABLE history DROP COLUMN prompt_version;
+ALTER TABLE history DROP COLUMN content_hash;
@@ -0,0 +1,4 @@
+ALTER TABLE history ADD COLUMN content_hash TEXT;
+ALTER TABLE history ADD COLUMN prompt_version TEXT;
+
+CREATE INDEX idx_history_cache_key ON history(content_hash, style_id, prompt_version, model);
@@ -0,0 +1,5 @@
+ALTER TABLE history DROP COLUMN publish_date;
+ALTER TABLE history DROP COLUMN image_url;
+ALTER TABLE history DROP COLUMN excerpt;
+ALTER TABLE history DROP COLUMN author;
+ALTER TABLE history DROP COLUMN site_name;
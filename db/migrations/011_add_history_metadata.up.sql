@@ -0,0 +1,5 @@
+ALTER TABLE history ADD COLUMN site_name TEXT;
+ALTER TABLE history ADD COLUMN author TEXT;
+ALTER TABLE history ADD COLUMN excerpt TEXT;
+ALTER TABLE history ADD COLUMN image_url TEXT;
+ALTER TABLE history ADD COLUMN publish_date DATETIME;
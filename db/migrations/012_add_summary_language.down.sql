@@ -0,0 +1,5 @@
+DROP INDEX idx_history_translated_from;
+
+ALTER TABLE history DROP COLUMN translated_from;
+ALTER TABLE history DROP COLUMN summary_language;
+ALTER TABLE summarization_styles DROP COLUMN default_language;
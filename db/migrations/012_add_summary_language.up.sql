@@ -0,0 +1,5 @@
+ALTER TABLE summarization_styles ADD COLUMN default_language TEXT;
+ALTER TABLE history ADD COLUMN summary_language TEXT;
+ALTER TABLE history ADD COLUMN translated_from INTEGER REFERENCES history(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_history_translated_from ON history(translated_from, summary_language);
@@ -0,0 +1,11 @@
+CREATE TABLE domain_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    domain TEXT NOT NULL UNIQUE,
+    include_selector TEXT,
+    exclude_selector TEXT,
+    title_selector TEXT,
+    author_selector TEXT,
+    user_agent TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) HandleGetBreakers(c *gin.Context) {
+	statuses := h.llmChain.Status()
+
+	targets := make([]LLMTargetStatus, len(statuses))
+	for i, s := range statuses {
+		targets[i] = LLMTargetStatus{
+			Provider:  s.Provider,
+			Model:     s.Model,
+			State:     s.State,
+			Failures:  s.Failures,
+			LastError: s.LastError,
+		}
+		if s.OpenedAt != nil {
+			targets[i].OpenedAt = s.OpenedAt.Format(time.RFC3339)
+		}
+	}
+
+	c.JSON(http.StatusOK, targets)
+}
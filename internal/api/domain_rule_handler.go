@@ -0,0 +1,151 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"anpurnama/summarizer-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+func (h *Handler) HandleListDomainRules(c *gin.Context) {
+	repoRules, err := h.ruleRepo.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch domain rules: " + err.Error()})
+		return
+	}
+
+	rules := make([]DomainRule, len(repoRules))
+	for i, r := range repoRules {
+		rules[i] = toAPIDomainRule(r)
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+func (h *Handler) HandleGetDomainRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	rule, err := h.ruleRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch domain rule: " + err.Error()})
+		return
+	}
+
+	if rule == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Code: ErrCodeNotFound, Error: "Domain rule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toAPIDomainRule(*rule))
+}
+
+func (h *Handler) HandleCreateDomainRule(c *gin.Context) {
+	var req DomainRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: ErrCodeInvalidRequest, Error: "Invalid request body: " + err.Error()})
+		return
+	}
+
+	rule := req.toRepositoryDomainRule()
+	if err := h.ruleRepo.Create(c.Request.Context(), rule); err != nil {
+		c.JSON(domainRuleErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, toAPIDomainRule(*rule))
+}
+
+func (h *Handler) HandleUpdateDomainRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var req DomainRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: ErrCodeInvalidRequest, Error: "Invalid request body: " + err.Error()})
+		return
+	}
+
+	rule := req.toRepositoryDomainRule()
+	rule.ID = id
+	if err := h.ruleRepo.Update(c.Request.Context(), rule); err != nil {
+		c.JSON(domainRuleErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toAPIDomainRule(*rule))
+}
+
+func (h *Handler) HandleDeleteDomainRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	if err := h.ruleRepo.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(domainRuleErrorResponse(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func domainRuleErrorResponse(err error) (int, ErrorResponse) {
+	var validationErrs validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrs):
+		return http.StatusBadRequest, ErrorResponse{Code: ErrCodeInvalidRequest, Error: "Invalid domain rule: " + err.Error()}
+	case errors.Is(err, repository.ErrInvalidSelector):
+		return http.StatusBadRequest, ErrorResponse{Code: ErrCodeInvalidRequest, Error: err.Error()}
+	case errors.Is(err, repository.ErrDomainRuleNotFound):
+		return http.StatusNotFound, ErrorResponse{Code: ErrCodeNotFound, Error: "Domain rule not found"}
+	case errors.Is(err, repository.ErrDomainRuleTaken):
+		return http.StatusConflict, ErrorResponse{Code: ErrCodeDomainRuleTaken, Error: "Domain already has a rule"}
+	}
+	return http.StatusInternalServerError, ErrorResponse{Code: ErrCodeInternal, Error: "Failed to save domain rule: " + err.Error()}
+}
+
+func (r DomainRuleRequest) toRepositoryDomainRule() *repository.DomainRule {
+	optional := func(s string) *string {
+		if s = strings.TrimSpace(s); s == "" {
+			return nil
+		}
+		return &s
+	}
+
+	return &repository.DomainRule{
+		Domain:          strings.TrimSuffix(strings.ToLower(strings.TrimSpace(r.Domain)), "."),
+		IncludeSelector: optional(r.IncludeSelector),
+		ExcludeSelector: optional(r.ExcludeSelector),
+		TitleSelector:   optional(r.TitleSelector),
+		AuthorSelector:  optional(r.AuthorSelector),
+		UserAgent:       optional(r.UserAgent),
+	}
+}
+
+func toAPIDomainRule(r repository.DomainRule) DomainRule {
+	return DomainRule{
+		ID:              strconv.Itoa(r.ID),
+		Domain:          r.Domain,
+		IncludeSelector: stringValue(r.IncludeSelector),
+		ExcludeSelector: stringValue(r.ExcludeSelector),
+		TitleSelector:   stringValue(r.TitleSelector),
+		AuthorSelector:  stringValue(r.AuthorSelector),
+		UserAgent:       stringValue(r.UserAgent),
+		CreatedAt:       r.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       r.UpdatedAt.Format(time.RFC3339),
+	}
+}
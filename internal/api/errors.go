@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"anpurnama/summarizer-backend/internal/service"
+	"anpurnama/summarizer-backend/internal/service/extractor"
+	"anpurnama/summarizer-backend/internal/service/llm"
+)
+
+const (
+	ErrCodeInvalidRequest      = "invalid_request"
+	ErrCodeInvalidStyle        = "invalid_style"
+	ErrCodeExtractionFailed    = "extraction_failed"
+	ErrCodeSummarizationFailed = "summarization_failed"
+	ErrCodeSaveFailed          = "save_failed"
+	ErrCodeNotFound            = "not_found"
+	ErrCodeStyleNameTaken      = "style_name_taken"
+	ErrCodeStyleInUse          = "style_in_use"
+	ErrCodeDomainRuleTaken     = "domain_rule_taken"
+	ErrCodeInternal            = "internal_error"
+	ErrCodeUpstreamRateLimited = "upstream_rate_limited"
+	ErrCodeUpstreamUnavailable = "upstream_unavailable"
+	ErrCodeUpstreamTimeout     = "upstream_timeout"
+	ErrCodeUpstreamAuth        = "upstream_auth_failed"
+	ErrCodeUpstreamRejected    = "upstream_rejected"
+	ErrCodeContentTooLong      = "content_too_long"
+	ErrCodeEmptyDocument       = "empty_document"
+	ErrCodeUnsupportedDocument = "unsupported_document"
+	ErrCodeEncryptedPDF        = "encrypted_pdf"
+	ErrCodeImageOnlyPDF        = "image_only_pdf"
+	ErrCodeBlockedDestination  = "blocked_destination"
+	ErrCodeDocumentTooLarge    = "document_too_large"
+	ErrCodeUnsupportedContent  = "unsupported_content_type"
+	ErrCodeDisallowedByRobots  = "disallowed_by_robots"
+)
+
+func summarizeErrorResponse(err error) (int, ErrorResponse) {
+	if errors.Is(err, service.ErrStyleNotFound) {
+		return http.StatusBadRequest, ErrorResponse{Code: ErrCodeInvalidStyle, Error: "Invalid style: " + err.Error()}
+	}
+
+	var stageErr *service.StageError
+	if errors.As(err, &stageErr) {
+		switch stageErr.Stage {
+		case service.StageExtracting:
+			status, code := extractionErrorStatus(stageErr.Err)
+			return status, ErrorResponse{Code: code, Error: "Failed to extract content: " + stageErr.Err.Error()}
+		case service.StageSummarizing:
+			status, code := summarizationErrorStatus(stageErr.Err)
+			return status, ErrorResponse{Code: code, Error: "Failed to generate summary: " + stageErr.Err.Error()}
+		case service.StageSaving:
+			return http.StatusInternalServerError, ErrorResponse{Code: ErrCodeSaveFailed, Error: "Failed to save history: " + stageErr.Err.Error()}
+		}
+	}
+
+	return http.StatusInternalServerError, ErrorResponse{Code: ErrCodeInternal, Error: err.Error()}
+}
+
+func extractionErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, extractor.ErrEmptyDocument):
+		return http.StatusUnprocessableEntity, ErrCodeEmptyDocument
+	case errors.Is(err, extractor.ErrUnsupportedDocument):
+		return http.StatusUnsupportedMediaType, ErrCodeUnsupportedDocument
+	case errors.Is(err, extractor.ErrEncryptedPDF):
+		return http.StatusUnprocessableEntity, ErrCodeEncryptedPDF
+	case errors.Is(err, extractor.ErrImageOnlyPDF):
+		return http.StatusUnprocessableEntity, ErrCodeImageOnlyPDF
+	case errors.Is(err, extractor.ErrBlockedDestination):
+		return http.StatusForbidden, ErrCodeBlockedDestination
+	case errors.Is(err, extractor.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge, ErrCodeDocumentTooLarge
+	case errors.Is(err, extractor.ErrUnsupportedContentType):
+		return http.StatusUnsupportedMediaType, ErrCodeUnsupportedContent
+	case errors.Is(err, extractor.ErrDisallowedByRobots):
+		return http.StatusForbidden, ErrCodeDisallowedByRobots
+	}
+	return http.StatusInternalServerError, ErrCodeExtractionFailed
+}
+
+func summarizationErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, llm.ErrRateLimited):
+		return http.StatusTooManyRequests, ErrCodeUpstreamRateLimited
+	case errors.Is(err, llm.ErrUpstreamTimeout), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, ErrCodeUpstreamTimeout
+	case errors.Is(err, llm.ErrUpstreamUnavailable), errors.Is(err, llm.ErrNoHealthyTarget):
+		return http.StatusServiceUnavailable, ErrCodeUpstreamUnavailable
+	case errors.Is(err, llm.ErrUpstreamAuth):
+		return http.StatusBadGateway, ErrCodeUpstreamAuth
+	case errors.Is(err, llm.ErrContextLength):
+		return http.StatusRequestEntityTooLarge, ErrCodeContentTooLong
+	case errors.Is(err, llm.ErrUpstreamRejected):
+		return http.StatusBadGateway, ErrCodeUpstreamRejected
+	}
+	return http.StatusInternalServerError, ErrCodeSummarizationFailed
+}
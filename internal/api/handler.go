@@ -4,6 +4,10 @@ import (
 	"anpurnama/summarizer-backend/internal/repository"
 	"anpurnama/summarizer-backend/internal/service"
 	"anpurnama/summarizer-backend/internal/service/extractor"
+	"anpurnama/summarizer-backend/internal/service/jobs"
+	"anpurnama/summarizer-backend/internal/service/llm"
+	"anpurnama/summarizer-backend/internal/service/semantic"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -12,93 +16,244 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// maxBatchItems keeps a batch small enough to finish within one request; larger
+// workloads belong in the job queue.
+const maxBatchItems = 20
+
+// maxSemanticResults caps how many matches one semantic search returns.
+const maxSemanticResults = 50
+
 type Handler struct {
 	historyRepo repository.HistoryRepository
 	styleRepo   repository.StyleRepository
-	extractor   extractor.ContentExtractor
-	summarizer  service.Summarizer
+	jobRepo     repository.JobRepository
+	usageRepo   repository.UsageRepository
+	ruleRepo    repository.DomainRuleRepository
+	pipeline    *service.Pipeline
+	jobQueue    *jobs.Queue
+	llmChain    *llm.Chain
+	semantic    *semantic.Index
+
+	batchConcurrency int
+	maxBodyBytes     int64
+}
+
+type Dependencies struct {
+	HistoryRepo      repository.HistoryRepository
+	StyleRepo        repository.StyleRepository
+	JobRepo          repository.JobRepository
+	UsageRepo        repository.UsageRepository
+	DomainRuleRepo   repository.DomainRuleRepository
+	Pipeline         *service.Pipeline
+	JobQueue         *jobs.Queue
+	LLMChain         *llm.Chain
+	SemanticIndex    *semantic.Index
+	BatchConcurrency int
+	MaxBodyBytes     int64
 }
 
-func NewHandler(
-	historyRepo repository.HistoryRepository,
-	styleRepo repository.StyleRepository,
-	extractor extractor.ContentExtractor,
-	summarizer service.Summarizer,
-) *Handler {
+func NewHandler(deps Dependencies) *Handler {
 	return &Handler{
-		historyRepo: historyRepo,
-		styleRepo:   styleRepo,
-		extractor:   extractor,
-		summarizer:  summarizer,
+		historyRepo: deps.HistoryRepo,
+		styleRepo:   deps.StyleRepo,
+		jobRepo:     deps.JobRepo,
+		usageRepo:   deps.UsageRepo,
+		ruleRepo:    deps.DomainRuleRepo,
+		pipeline:    deps.Pipeline,
+		jobQueue:    deps.JobQueue,
+		llmChain:    deps.LLMChain,
+		semantic:    deps.SemanticIndex,
+
+		batchConcurrency: deps.BatchConcurrency,
+		maxBodyBytes:     deps.MaxBodyBytes,
 	}
 }
 
 func (h *Handler) HandleSummarize(c *gin.Context) {
-	reqInterface, exists := c.Get("summarizeRequest")
-	if !exists {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request"})
+	req, ok := summarizeRequestFrom(c)
+	if !ok {
 		return
 	}
 
-	req, ok := reqInterface.(SummarizeRequest)
-	if !ok {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request type"})
+	if wantsEventStream(c) {
+		h.streamSummarize(c, req)
 		return
 	}
 
-	// Extract content from URL
-	extracted, err := h.extractor.Extract(c.Request.Context(), req.URL)
+	result, err := h.pipeline.Run(c.Request.Context(), req.toInput(apiKeyFingerprint(c)), service.Progress{})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to extract content: " + err.Error()})
+		c.JSON(summarizeErrorResponse(err))
 		return
 	}
 
-	// Generate summary using Gemini
-	styleName := req.Style
-	if styleName == "" {
-		styleName = "concise" // Change default to an existing style
-	}
+	c.JSON(http.StatusOK, SummarizeResponse{
+		Summary:         result.History.Summary,
+		Title:           stringValue(result.History.Title),
+		URL:             result.History.URL,
+		ArticleMetadata: articleMetadata(*result.History),
+		Cached:          result.Cached,
+	})
+}
 
-	// Get style ID before generating summary
-	style, err := h.styleRepo.GetByName(c.Request.Context(), styleName)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid style: " + err.Error()})
+func (h *Handler) HandleSummarizeBatch(c *gin.Context) {
+	var req BatchSummarizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: ErrCodeInvalidRequest, Error: "Invalid request body: " + err.Error()})
 		return
 	}
 
-	summary, err := h.summarizer.Summarize(c.Request.Context(), extracted.Content, styleName)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to generate summary: " + err.Error()})
+	if len(req.Items) > maxBatchItems {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:  ErrCodeInvalidRequest,
+			Error: "Too many items, maximum is " + strconv.Itoa(maxBatchItems),
+		})
 		return
 	}
 
-	// Save to history
-	history := &repository.History{
-		URL:     req.URL,
-		Title:   &extracted.Title,
-		Content: extracted.Content,
-		Summary: summary,
-		StyleID: &style.ID,
+	concurrency := h.batchConcurrency
+	if req.Concurrency > 0 && req.Concurrency < concurrency {
+		concurrency = req.Concurrency
 	}
 
-	// Ensure language code meets ISO 639-1 format
-	if extracted.Language != "" {
-		if len(extracted.Language) == 2 {
-			lowerLang := strings.ToLower(extracted.Language)
-			history.Language = &lowerLang
+	var inputs []service.SummarizeInput
+	var positions []int
+	results := make([]BatchItemResult, len(req.Items))
+	for i, item := range req.Items {
+		results[i] = BatchItemResult{URL: item.URL, Style: item.Style}
+		if item.URL == "" {
+			results[i].Error = &ErrorResponse{Code: ErrCodeInvalidRequest, Error: "URL is required"}
+			continue
 		}
+		inputs = append(inputs, service.SummarizeInput{
+			URL:     item.URL,
+			Style:   item.Style,
+			Options: service.SummarizeOptions{Refresh: req.Refresh, TargetLanguage: strings.ToLower(req.TargetLanguage)},
+			APIKey:  apiKeyFingerprint(c),
+		})
+		positions = append(positions, i)
 	}
 
-	if err := h.historyRepo.Create(c.Request.Context(), history); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save history: " + err.Error()})
+	for i, item := range h.pipeline.RunBatch(c.Request.Context(), inputs, concurrency) {
+		result := &results[positions[i]]
+		if item.Err != nil {
+			_, errResp := summarizeErrorResponse(item.Err)
+			result.Error = &errResp
+			continue
+		}
+
+		result.HistoryID = strconv.Itoa(item.Result.History.ID)
+		result.Summary = item.Result.History.Summary
+		result.Title = stringValue(item.Result.History.Title)
+		result.Cached = item.Result.Cached
+	}
+
+	c.JSON(http.StatusOK, BatchSummarizeResponse{Results: results})
+}
+
+func (h *Handler) streamSummarize(c *gin.Context, req SummarizeRequest) {
+	if !h.pipeline.SupportsStreaming() {
+		c.JSON(http.StatusNotAcceptable, ErrorResponse{Error: "Streaming is not supported by the summarizer"})
 		return
 	}
 
-	c.JSON(http.StatusOK, SummarizeResponse{
-		Summary: summary,
-		Title:   *history.Title,
-		URL:     req.URL,
+	ctx := c.Request.Context()
+	stream := &eventStream{c: c}
+	input := req.toInput(apiKeyFingerprint(c))
+
+	result, err := h.pipeline.Run(ctx, input, service.Progress{
+		OnExtracted: func(extracted *extractor.ExtractedContent) {
+			stream.send("metadata", SummarizeMetadataEvent{
+				Title:              extracted.Title,
+				URL:                input.Source(),
+				SiteName:           extracted.SiteName,
+				Author:             extracted.Author,
+				Excerpt:            extracted.Excerpt,
+				ImageURL:           extracted.ImageURL,
+				PublishDate:        extracted.PublishDate,
+				Language:           extracted.Language,
+				LanguageConfidence: extracted.LanguageConfidence,
+			})
+		},
+		OnDelta: func(delta string) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			stream.send("delta", SummarizeDeltaEvent{Content: delta})
+			return nil
+		},
 	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		status, errResp := summarizeErrorResponse(err)
+		if !stream.started {
+			c.JSON(status, errResp)
+			return
+		}
+		stream.send("error", errResp)
+		return
+	}
+
+	stream.send("done", SummarizeDoneEvent{
+		HistoryID: strconv.Itoa(result.History.ID),
+		Summary:   result.History.Summary,
+		Title:     stringValue(result.History.Title),
+		URL:       result.History.URL,
+		Cached:    result.Cached,
+	})
+}
+
+type eventStream struct {
+	c       *gin.Context
+	started bool
+}
+
+func (s *eventStream) send(name string, data any) {
+	if !s.started {
+		s.c.Header("Content-Type", "text/event-stream")
+		s.c.Header("Cache-Control", "no-cache")
+		s.c.Header("Connection", "keep-alive")
+		s.c.Header("X-Accel-Buffering", "no")
+		s.c.Status(http.StatusOK)
+		s.started = true
+	}
+	s.c.SSEvent(name, data)
+	s.c.Writer.Flush()
+}
+
+func wantsEventStream(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+}
+
+func (r SummarizeRequest) toInput(apiKey string) service.SummarizeInput {
+	return service.SummarizeInput{
+		URL:      r.URL,
+		Document: r.document,
+		Style:    r.Style,
+		Options: service.SummarizeOptions{
+			MaxWords:       r.MaxWords,
+			Params:         r.Params,
+			Refresh:        r.Refresh,
+			TargetLanguage: strings.ToLower(r.TargetLanguage),
+		},
+		APIKey: apiKey,
+	}
+}
+
+func summarizeRequestFrom(c *gin.Context) (SummarizeRequest, bool) {
+	reqInterface, exists := c.Get("summarizeRequest")
+	if !exists {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request"})
+		return SummarizeRequest{}, false
+	}
+
+	req, ok := reqInterface.(SummarizeRequest)
+	if !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request type"})
+		return SummarizeRequest{}, false
+	}
+	return req, true
 }
 
 func (h *Handler) HandleGetHistory(c *gin.Context) {
@@ -161,6 +316,35 @@ func (h *Handler) HandleGetHistoryById(c *gin.Context) {
 	c.JSON(http.StatusOK, toAPIHistory(*history))
 }
 
+func (h *Handler) HandleTranslateHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var req TranslateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: ErrCodeInvalidRequest, Error: "Invalid request body: " + err.Error()})
+		return
+	}
+
+	translated, err := h.pipeline.Translate(c.Request.Context(), id, strings.ToLower(req.TargetLanguage), apiKeyFingerprint(c))
+	switch {
+	case errors.Is(err, service.ErrHistoryNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Code: ErrCodeNotFound, Error: "History not found"})
+		return
+	case errors.Is(err, service.ErrUnsupportedLanguage):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: ErrCodeInvalidRequest, Error: err.Error()})
+		return
+	case err != nil:
+		c.JSON(summarizeErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toAPIHistory(*translated))
+}
+
 func (h *Handler) HandleSearch(c *gin.Context) {
 	query := c.Query("q")
 	if query == "" {
@@ -183,18 +367,78 @@ func (h *Handler) HandleSearch(c *gin.Context) {
 		}
 	}
 
-	results, err := h.historyRepo.Search(c.Request.Context(), query, limit, offset)
+	search := repository.HistorySearch{Query: query}
+	if columns := c.Query("columns"); columns != "" {
+		search.Columns = strings.Split(columns, ",")
+	}
+
+	hits, err := h.historyRepo.Search(c.Request.Context(), search, limit, offset)
+	if err != nil {
+		c.JSON(searchErrorResponse(err))
+		return
+	}
+
+	totalSize, err := h.historyRepo.CountSearch(c.Request.Context(), search)
+	if err != nil {
+		c.JSON(searchErrorResponse(err))
+		return
+	}
+
+	results := make([]SearchResult, len(hits))
+	for i, hit := range hits {
+		results[i] = SearchResult{
+			History: toAPIHistory(hit.History),
+			Snippet: hit.Snippet,
+			Score:   hit.Score,
+		}
+	}
+
+	c.JSON(http.StatusOK, SearchResponse{
+		Results:   results,
+		TotalSize: totalSize,
+	})
+}
+
+func (h *Handler) HandleSemanticSearch(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Search query is required"})
+		return
+	}
+
+	limit := 10 // Default limit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = min(l, maxSemanticResults)
+		}
+	}
+
+	matches, err := h.semantic.Search(c.Request.Context(), query, limit)
+	if errors.Is(err, semantic.ErrEmptyQuery) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Search query is required"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to search: " + err.Error()})
 		return
 	}
 
-	histories := make([]History, len(results))
-	for i, h := range results {
-		histories[i] = toAPIHistory(h)
+	results := make([]SemanticSearchResult, len(matches))
+	for i, match := range matches {
+		results[i] = SemanticSearchResult{
+			History: toAPIHistory(match.History),
+			Score:   match.Score,
+		}
 	}
 
-	c.JSON(http.StatusOK, histories)
+	c.JSON(http.StatusOK, SemanticSearchResponse{Results: results})
+}
+
+func searchErrorResponse(err error) (int, ErrorResponse) {
+	if errors.Is(err, repository.ErrInvalidSearchQuery) {
+		return http.StatusBadRequest, ErrorResponse{Code: ErrCodeInvalidRequest, Error: err.Error()}
+	}
+	return http.StatusInternalServerError, ErrorResponse{Error: "Failed to search: " + err.Error()}
 }
 
 func toAPIHistory(h repository.History) History {
@@ -202,12 +446,46 @@ func toAPIHistory(h repository.History) History {
 	if h.Title != nil {
 		title = *h.Title
 	}
+	translatedFrom := ""
+	if h.TranslatedFrom != nil {
+		translatedFrom = strconv.Itoa(*h.TranslatedFrom)
+	}
 
 	return History{
-		ID:        strconv.Itoa(h.ID),
-		URL:       h.URL,
-		Summary:   h.Summary,
-		Title:     title,
-		CreatedAt: h.CreatedAt.Format(time.RFC3339),
+		ID:               strconv.Itoa(h.ID),
+		URL:              h.URL,
+		Summary:          h.Summary,
+		Title:            title,
+		ArticleMetadata:  articleMetadata(h),
+		Language:         stringValue(h.Language),
+		SummaryLanguage:  stringValue(h.SummaryLanguage),
+		TranslatedFrom:   translatedFrom,
+		ChunkCount:       h.ChunkCount,
+		Provider:         stringValue(h.Provider),
+		Model:            stringValue(h.Model),
+		PromptTokens:     h.PromptTokens,
+		CompletionTokens: h.CompletionTokens,
+		Cost:             h.Cost,
+		CreatedAt:        h.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func articleMetadata(h repository.History) ArticleMetadata {
+	metadata := ArticleMetadata{
+		SiteName: stringValue(h.SiteName),
+		Author:   stringValue(h.Author),
+		Excerpt:  stringValue(h.Excerpt),
+		ImageURL: stringValue(h.ImageURL),
+	}
+	if h.PublishDate != nil {
+		metadata.PublishDate = h.PublishDate.Format(time.RFC3339)
+	}
+	return metadata
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
 	}
+	return *s
 }
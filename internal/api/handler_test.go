@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"anpurnama/summarizer-backend/internal/repository"
+	"anpurnama/summarizer-backend/internal/service"
+	"anpurnama/summarizer-backend/internal/service/extractor"
+	"anpurnama/summarizer-backend/internal/service/prompt"
+	"anpurnama/summarizer-backend/internal/service/semantic"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeHistories struct {
+	repository.HistoryRepository
+	mu      sync.Mutex
+	created []*repository.History
+}
+
+func (r *fakeHistories) FindCached(context.Context, repository.SummaryCacheKey) (*repository.History, error) {
+	return nil, nil
+}
+
+func (r *fakeHistories) Create(_ context.Context, history *repository.History) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.created = append(r.created, history)
+	history.ID = len(r.created)
+	return nil
+}
+
+type fakeStyles struct {
+	repository.StyleRepository
+}
+
+func (fakeStyles) GetByName(_ context.Context, name string) (*repository.Style, error) {
+	return &repository.Style{ID: 1, Name: name, PromptTemplate: "{{.Content}}"}, nil
+}
+
+// fakeExtractor returns the page registered for a URL, or fails for any other.
+type fakeExtractor struct {
+	pages map[string]extractor.ExtractedContent
+}
+
+func (e fakeExtractor) Extract(_ context.Context, url string) (*extractor.ExtractedContent, error) {
+	page, ok := e.pages[url]
+	if !ok {
+		return nil, extractor.ErrEmptyDocument
+	}
+	return &page, nil
+}
+
+func (e fakeExtractor) ExtractDocument(context.Context, extractor.Document) (*extractor.ExtractedContent, error) {
+	return nil, extractor.ErrUnsupportedDocument
+}
+
+type fakeSummarizer struct{}
+
+func (fakeSummarizer) Summarize(_ context.Context, _ string, data prompt.Data) (*service.Summary, error) {
+	return &service.Summary{Text: "Summary of " + data.Content, Chunks: 1, Provider: "fake", Model: "fake-model"}, nil
+}
+
+func (fakeSummarizer) Translate(_ context.Context, text, _ string) (*service.Summary, error) {
+	return &service.Summary{Text: text}, nil
+}
+
+func (fakeSummarizer) PreferredModel() string {
+	return "fake-model"
+}
+
+func newTestRouter(pages map[string]extractor.ExtractedContent) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	histories := &fakeHistories{}
+	pipeline := service.NewPipeline(histories, fakeStyles{}, fakeExtractor{pages: pages}, fakeSummarizer{}, nil)
+	return SetupRouter(NewHandler(Dependencies{
+		HistoryRepo:      histories,
+		StyleRepo:        fakeStyles{},
+		Pipeline:         pipeline,
+		BatchConcurrency: 2,
+	}))
+}
+
+func TestSummarizeBatchWithoutTitle(t *testing.T) {
+	router := newTestRouter(map[string]extractor.ExtractedContent{
+		"https://example.com/titled":   {Title: "Titled", Content: "first page"},
+		"https://example.com/untitled": {Content: "second page"},
+	})
+
+	body := `{"items":[{"url":"https://example.com/titled"},{"url":"https://example.com/untitled"},{"url":"https://example.com/missing"}]}`
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/summarize/batch", strings.NewReader(body)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body)
+	}
+	var resp BatchSummarizeResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("got %d results, want 3", len(resp.Results))
+	}
+
+	if got := resp.Results[0]; got.Error != nil || got.Title != "Titled" {
+		t.Errorf("titled result = %+v", got)
+	}
+	if got := resp.Results[1]; got.Error != nil || got.Title != "" || got.Summary != "Summary of second page" {
+		t.Errorf("untitled result = %+v, want a summary without a title", got)
+	}
+	if got := resp.Results[2]; got.Error == nil || got.Error.Code != ErrCodeEmptyDocument {
+		t.Errorf("missing result = %+v, want its own extraction error", got)
+	}
+}
+
+func TestSummarizeBatchRejectsTooManyItems(t *testing.T) {
+	router := newTestRouter(nil)
+	items := strings.Repeat(`{"url":"https://example.com/page"},`, maxBatchItems+1)
+	body := `{"items":[` + strings.TrimSuffix(items, ",") + `]}`
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/summarize/batch", strings.NewReader(body)))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	var resp ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Code != ErrCodeInvalidRequest {
+		t.Errorf("code = %q, want %q", resp.Code, ErrCodeInvalidRequest)
+	}
+}
+
+// vectorStore holds one identical embedding per history, so every history
+// matches a semantic search equally well.
+type vectorStore struct {
+	repository.HistoryRepository
+	repository.EmbeddingRepository
+	count int
+}
+
+func (s vectorStore) ListByModel(_ context.Context, model string) ([]repository.Embedding, error) {
+	embeddings := make([]repository.Embedding, s.count)
+	for i := range embeddings {
+		embeddings[i] = repository.Embedding{HistoryID: i + 1, Kind: repository.EmbeddingKindSummary, Model: model, Vector: []float32{1}}
+	}
+	return embeddings, nil
+}
+
+func (s vectorStore) ListWithStylesByIDs(_ context.Context, ids []int) ([]repository.History, error) {
+	histories := make([]repository.History, len(ids))
+	for i, id := range ids {
+		histories[i] = repository.History{ID: id, Summary: "summary"}
+	}
+	return histories, nil
+}
+
+type constantEmbedder struct{}
+
+func (constantEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i := range vectors {
+		vectors[i] = []float32{1}
+	}
+	return vectors, nil
+}
+
+func (constantEmbedder) Model() string {
+	return "constant"
+}
+
+func TestSemanticSearchCapsLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := vectorStore{count: 3 * maxSemanticResults}
+	router := SetupRouter(NewHandler(Dependencies{
+		HistoryRepo:   &fakeHistories{},
+		StyleRepo:     fakeStyles{},
+		SemanticIndex: semantic.NewIndex(store, store, constantEmbedder{}),
+	}))
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 10},
+		{"&limit=5", 5},
+		{"&limit=100000", maxSemanticResults},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/search/semantic?q=anything"+tt.query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body = %s", w.Code, w.Body)
+		}
+		var resp SemanticSearchResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if len(resp.Results) != tt.want {
+			t.Errorf("%q returned %d results, want %d", tt.query, len(resp.Results), tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"anpurnama/summarizer-backend/internal/repository"
+	"anpurnama/summarizer-backend/internal/service/jobs"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) HandleCreateJob(c *gin.Context) {
+	req, ok := summarizeRequestFrom(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.jobQueue.Enqueue(c.Request.Context(), req.toInput(apiKeyFingerprint(c)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create job: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, toAPIJob(*job))
+}
+
+func (h *Handler) HandleGetJob(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	job, err := h.jobRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch job: " + err.Error()})
+		return
+	}
+
+	if job == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Job not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toAPIJob(*job))
+}
+
+func (h *Handler) HandleCancelJob(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	job, err := h.jobQueue.Cancel(c.Request.Context(), id)
+	if errors.Is(err, jobs.ErrJobNotFound) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Job not found"})
+		return
+	}
+	if errors.Is(err, jobs.ErrJobFinished) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Job already " + job.Status})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to cancel job: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, toAPIJob(*job))
+}
+
+func toAPIJob(j repository.Job) Job {
+	job := Job{
+		ID:        strconv.Itoa(j.ID),
+		URL:       j.URL,
+		Status:    j.Status,
+		Stage:     j.Stage,
+		Attempts:  j.Attempts,
+		CreatedAt: j.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: j.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if j.Style != nil {
+		job.Style = *j.Style
+	}
+	if j.Error != nil {
+		job.Error = *j.Error
+	}
+	if j.HistoryID != nil {
+		job.HistoryID = strconv.Itoa(*j.HistoryID)
+	}
+	if j.StartedAt != nil {
+		job.StartedAt = j.StartedAt.Format(time.RFC3339)
+	}
+	if j.FinishedAt != nil {
+		job.FinishedAt = j.FinishedAt.Format(time.RFC3339)
+	}
+
+	return job
+}
@@ -1,16 +1,43 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"sync"
+
+	"anpurnama/summarizer-backend/internal/service/extractor"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 )
 
+// formOverheadBytes leaves room for the multipart framing and other fields
+// around an upload of the largest accepted size.
+const formOverheadBytes = 64 << 10
+
+var registerValidatorsOnce sync.Once
+
+// registerValidators adds the "language" binding tag, which accepts ISO 639-1
+// codes the extractor knows a name for.
+func registerValidators() {
+	registerValidatorsOnce.Do(func() {
+		if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			v.RegisterValidation("language", func(fl validator.FieldLevel) bool {
+				return extractor.LanguageName(fl.Field().String()) != ""
+			})
+		}
+	})
+}
+
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -35,26 +62,83 @@ func ErrorHandler() gin.HandlerFunc {
 	}
 }
 
-func validateSummarizeRequest() gin.HandlerFunc {
+func validateSummarizeRequest(maxBodyBytes int64) gin.HandlerFunc {
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = extractor.DefaultMaxBodyBytes
+	}
 	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes+formOverheadBytes)
+
 		var req SummarizeRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Error: "Invalid request body: " + err.Error(),
-			})
-			c.Abort()
+		if err := c.ShouldBind(&req); err != nil {
+			abortWithBodyError(c, err)
 			return
 		}
 
-		if req.URL == "" {
+		sources := 0
+		for _, set := range []bool{req.URL != "", req.Text != "", req.File != nil} {
+			if set {
+				sources++
+			}
+		}
+		if sources != 1 {
 			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Error: "URL is required",
+				Code:  ErrCodeInvalidRequest,
+				Error: "Exactly one of url, text or file is required",
 			})
 			c.Abort()
 			return
 		}
 
+		switch {
+		case req.Text != "":
+			req.document = &extractor.Document{ContentType: "text/plain", Title: req.Title, Data: []byte(req.Text)}
+		case req.File != nil:
+			document, err := readUpload(req.File)
+			if err != nil {
+				abortWithBodyError(c, err)
+				return
+			}
+			document.Title = req.Title
+			req.document = document
+		}
+
 		c.Set("summarizeRequest", req)
 		c.Next()
 	}
 }
+
+func readUpload(header *multipart.FileHeader) (*extractor.Document, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &extractor.Document{
+		Name:        header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Data:        data,
+	}, nil
+}
+
+func abortWithBodyError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{
+			Code:  ErrCodeInvalidRequest,
+			Error: fmt.Sprintf("Request body exceeds %d bytes", tooLarge.Limit),
+		})
+	} else {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:  ErrCodeInvalidRequest,
+			Error: "Invalid request body: " + err.Error(),
+		})
+	}
+	c.Abort()
+}
@@ -1,21 +1,12 @@
 package api
 
 import (
-	"anpurnama/summarizer-backend/internal/repository"
-	"anpurnama/summarizer-backend/internal/service"
-	"anpurnama/summarizer-backend/internal/service/extractor"
-
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(
-	historyRepo repository.HistoryRepository,
-	styleRepo repository.StyleRepository,
-	extractor extractor.ContentExtractor,
-	summarizer service.Summarizer,
-) *gin.Engine {
+func SetupRouter(handler *Handler) *gin.Engine {
 	router := gin.Default()
-	handler := NewHandler(historyRepo, styleRepo, extractor, summarizer)
+	registerValidators()
 
 	// Enable CORS
 	router.Use(CORSMiddleware())
@@ -26,10 +17,28 @@ func SetupRouter(
 	// API routes group
 	api := router.Group("/api")
 	{
-		api.POST("/summarize", validateSummarizeRequest(), handler.HandleSummarize)
+		api.POST("/summarize", validateSummarizeRequest(handler.maxBodyBytes), handler.HandleSummarize)
+		api.POST("/summarize/batch", handler.HandleSummarizeBatch)
 		api.GET("/history", handler.HandleGetHistory)
 		api.GET("/history/:id", handler.HandleGetHistoryById)
+		api.POST("/history/:id/translate", handler.HandleTranslateHistory)
 		api.GET("/search", handler.HandleSearch)
+		api.GET("/search/semantic", handler.HandleSemanticSearch)
+		api.GET("/styles", handler.HandleListStyles)
+		api.GET("/styles/:id", handler.HandleGetStyle)
+		api.POST("/styles", handler.HandleCreateStyle)
+		api.PUT("/styles/:id", handler.HandleUpdateStyle)
+		api.DELETE("/styles/:id", handler.HandleDeleteStyle)
+		api.GET("/domain-rules", handler.HandleListDomainRules)
+		api.GET("/domain-rules/:id", handler.HandleGetDomainRule)
+		api.POST("/domain-rules", handler.HandleCreateDomainRule)
+		api.PUT("/domain-rules/:id", handler.HandleUpdateDomainRule)
+		api.DELETE("/domain-rules/:id", handler.HandleDeleteDomainRule)
+		api.POST("/jobs", validateSummarizeRequest(handler.maxBodyBytes), handler.HandleCreateJob)
+		api.GET("/jobs/:id", handler.HandleGetJob)
+		api.DELETE("/jobs/:id", handler.HandleCancelJob)
+		api.GET("/usage", handler.HandleGetUsage)
+		api.GET("/admin/breakers", handler.HandleGetBreakers)
 	}
 
 	return router
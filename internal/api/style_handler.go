@@ -0,0 +1,159 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"anpurnama/summarizer-backend/internal/repository"
+	"anpurnama/summarizer-backend/internal/service/prompt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+func (h *Handler) HandleListStyles(c *gin.Context) {
+	repoStyles, err := h.styleRepo.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch styles: " + err.Error()})
+		return
+	}
+
+	styles := make([]Style, len(repoStyles))
+	for i, s := range repoStyles {
+		styles[i] = toAPIStyle(s)
+	}
+
+	c.JSON(http.StatusOK, styles)
+}
+
+func (h *Handler) HandleGetStyle(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	style, err := h.styleRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch style: " + err.Error()})
+		return
+	}
+
+	if style == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Style not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toAPIStyle(*style))
+}
+
+func (h *Handler) HandleCreateStyle(c *gin.Context) {
+	var req StyleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: ErrCodeInvalidRequest, Error: "Invalid request body: " + err.Error()})
+		return
+	}
+	if err := prompt.Validate(req.PromptTemplate); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: ErrCodeInvalidRequest, Error: "Invalid prompt template: " + err.Error()})
+		return
+	}
+
+	style := req.toRepositoryStyle()
+	if err := h.styleRepo.Create(c.Request.Context(), style); err != nil {
+		c.JSON(styleErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, toAPIStyle(*style))
+}
+
+func (h *Handler) HandleUpdateStyle(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var req StyleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: ErrCodeInvalidRequest, Error: "Invalid request body: " + err.Error()})
+		return
+	}
+	if err := prompt.Validate(req.PromptTemplate); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: ErrCodeInvalidRequest, Error: "Invalid prompt template: " + err.Error()})
+		return
+	}
+
+	style := req.toRepositoryStyle()
+	style.ID = id
+	if err := h.styleRepo.Update(c.Request.Context(), style); err != nil {
+		c.JSON(styleErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toAPIStyle(*style))
+}
+
+func (h *Handler) HandleDeleteStyle(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	if err := h.styleRepo.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(styleErrorResponse(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func styleErrorResponse(err error) (int, ErrorResponse) {
+	var validationErrs validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrs):
+		return http.StatusBadRequest, ErrorResponse{Code: ErrCodeInvalidRequest, Error: "Invalid style: " + err.Error()}
+	case errors.Is(err, repository.ErrStyleNotFound):
+		return http.StatusNotFound, ErrorResponse{Code: ErrCodeNotFound, Error: "Style not found"}
+	case errors.Is(err, repository.ErrStyleNameTaken):
+		return http.StatusConflict, ErrorResponse{Code: ErrCodeStyleNameTaken, Error: "Style name already exists"}
+	case errors.Is(err, repository.ErrStyleInUse):
+		return http.StatusConflict, ErrorResponse{Code: ErrCodeStyleInUse, Error: "Style is still used by history entries"}
+	}
+	return http.StatusInternalServerError, ErrorResponse{Code: ErrCodeInternal, Error: "Failed to save style: " + err.Error()}
+}
+
+func (r StyleRequest) toRepositoryStyle() *repository.Style {
+	style := &repository.Style{
+		Name:           r.Name,
+		PromptTemplate: r.PromptTemplate,
+	}
+	if r.Description != "" {
+		style.Description = &r.Description
+	}
+	if r.DefaultLanguage != "" {
+		language := strings.ToLower(r.DefaultLanguage)
+		style.DefaultLanguage = &language
+	}
+	return style
+}
+
+func toAPIStyle(s repository.Style) Style {
+	description := ""
+	if s.Description != nil {
+		description = *s.Description
+	}
+
+	return Style{
+		ID:              strconv.Itoa(s.ID),
+		Name:            s.Name,
+		Description:     description,
+		PromptTemplate:  s.PromptTemplate,
+		DefaultLanguage: stringValue(s.DefaultLanguage),
+		CreatedAt:       s.CreatedAt.Format(time.RFC3339),
+	}
+}
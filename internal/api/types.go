@@ -1,17 +1,96 @@
 package api
 
+import (
+	"mime/multipart"
+
+	"anpurnama/summarizer-backend/internal/service/extractor"
+)
+
+// SummarizeRequest is sent as JSON, or as multipart/form-data when uploading a file.
+// Exactly one of URL, Text and File must be set.
 type SummarizeRequest struct {
-	URL   string `json:"url" binding:"required"`
-	Style string `json:"style,omitempty"`
+	URL      string                `json:"url,omitempty" form:"url"`
+	Text     string                `json:"text,omitempty" form:"text"`
+	Title    string                `json:"title,omitempty" form:"title"`
+	File     *multipart.FileHeader `json:"-" form:"file"`
+	Style    string                `json:"style,omitempty" form:"style"`
+	MaxWords int                   `json:"max_words,omitempty" form:"max_words" binding:"min=0"`
+	Params   map[string]string     `json:"params,omitempty" form:"-"`
+	Refresh  bool                  `json:"refresh,omitempty" form:"refresh"`
+	// TargetLanguage is an ISO 639-1 code and defaults to the style's default language.
+	TargetLanguage string `json:"target_language,omitempty" form:"target_language" binding:"omitempty,language"`
+
+	document *extractor.Document
 }
 
 type SummarizeResponse struct {
 	Summary string `json:"summary"`
 	Title   string `json:"title"`
 	URL     string `json:"url"`
+	ArticleMetadata
+	Cached bool `json:"cached"`
+}
+
+type ArticleMetadata struct {
+	SiteName    string `json:"site_name,omitempty"`
+	Author      string `json:"author,omitempty"`
+	Excerpt     string `json:"excerpt,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	PublishDate string `json:"publish_date,omitempty"`
+}
+
+type SummarizeMetadataEvent struct {
+	Title              string  `json:"title"`
+	URL                string  `json:"url"`
+	SiteName           string  `json:"site_name,omitempty"`
+	Author             string  `json:"author,omitempty"`
+	Excerpt            string  `json:"excerpt,omitempty"`
+	ImageURL           string  `json:"image_url,omitempty"`
+	PublishDate        string  `json:"publish_date,omitempty"`
+	Language           string  `json:"language,omitempty"`
+	LanguageConfidence float64 `json:"language_confidence,omitempty"`
+}
+
+type SummarizeDeltaEvent struct {
+	Content string `json:"content"`
+}
+
+type SummarizeDoneEvent struct {
+	HistoryID string `json:"history_id"`
+	Summary   string `json:"summary"`
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	Cached    bool   `json:"cached"`
+}
+
+type BatchSummarizeRequest struct {
+	Items          []BatchSummarizeItem `json:"items" binding:"required,min=1"`
+	Concurrency    int                  `json:"concurrency,omitempty"`
+	Refresh        bool                 `json:"refresh,omitempty"`
+	TargetLanguage string               `json:"target_language,omitempty" binding:"omitempty,language"`
+}
+
+type BatchSummarizeItem struct {
+	URL   string `json:"url"`
+	Style string `json:"style,omitempty"`
+}
+
+type BatchSummarizeResponse struct {
+	Results []BatchItemResult `json:"results"`
+}
+
+type BatchItemResult struct {
+	URL       string         `json:"url"`
+	Style     string         `json:"style,omitempty"`
+	HistoryID string         `json:"history_id,omitempty"`
+	Title     string         `json:"title,omitempty"`
+	Summary   string         `json:"summary,omitempty"`
+	Cached    bool           `json:"cached,omitempty"`
+	Error     *ErrorResponse `json:"error,omitempty"`
 }
 
 type ErrorResponse struct {
+	Code  string `json:"code,omitempty"`
 	Error string `json:"error"`
 }
 
@@ -20,10 +99,118 @@ type HistoryResponse struct {
 	TotalSize int       `json:"total_size"`
 }
 
+type SearchResponse struct {
+	Results   []SearchResult `json:"results"`
+	TotalSize int            `json:"total_size"`
+}
+
+type SearchResult struct {
+	History
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+}
+
+type SemanticSearchResponse struct {
+	Results []SemanticSearchResult `json:"results"`
+}
+
+type SemanticSearchResult struct {
+	History
+	Score float64 `json:"score"`
+}
+
 type History struct {
-	ID        string `json:"id"`
-	URL       string `json:"url"`
-	Summary   string `json:"summary"`
-	Title     string `json:"title"`
-	CreatedAt string `json:"created_at"`
+	ID      string `json:"id"`
+	URL     string `json:"url"`
+	Summary string `json:"summary"`
+	Title   string `json:"title"`
+	ArticleMetadata
+	Language         string   `json:"language,omitempty"`
+	SummaryLanguage  string   `json:"summary_language,omitempty"`
+	TranslatedFrom   string   `json:"translated_from,omitempty"`
+	ChunkCount       int      `json:"chunk_count"`
+	Provider         string   `json:"provider,omitempty"`
+	Model            string   `json:"model,omitempty"`
+	PromptTokens     *int     `json:"prompt_tokens,omitempty"`
+	CompletionTokens *int     `json:"completion_tokens,omitempty"`
+	Cost             *float64 `json:"cost,omitempty"`
+	CreatedAt        string   `json:"created_at"`
+}
+
+type Job struct {
+	ID         string `json:"id"`
+	URL        string `json:"url"`
+	Style      string `json:"style,omitempty"`
+	Status     string `json:"status"`
+	Stage      string `json:"stage"`
+	Attempts   int    `json:"attempts"`
+	Error      string `json:"error,omitempty"`
+	HistoryID  string `json:"history_id,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+	StartedAt  string `json:"started_at,omitempty"`
+	FinishedAt string `json:"finished_at,omitempty"`
+}
+
+type StyleRequest struct {
+	Name            string `json:"name" binding:"required"`
+	Description     string `json:"description,omitempty"`
+	PromptTemplate  string `json:"prompt_template" binding:"required"`
+	DefaultLanguage string `json:"default_language,omitempty" binding:"omitempty,language"`
+}
+
+type Style struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Description     string `json:"description,omitempty"`
+	PromptTemplate  string `json:"prompt_template"`
+	DefaultLanguage string `json:"default_language,omitempty"`
+	CreatedAt       string `json:"created_at"`
+}
+
+type DomainRuleRequest struct {
+	Domain          string `json:"domain" binding:"required"`
+	IncludeSelector string `json:"include_selector,omitempty"`
+	ExcludeSelector string `json:"exclude_selector,omitempty"`
+	TitleSelector   string `json:"title_selector,omitempty"`
+	AuthorSelector  string `json:"author_selector,omitempty"`
+	UserAgent       string `json:"user_agent,omitempty"`
+}
+
+type DomainRule struct {
+	ID              string `json:"id"`
+	Domain          string `json:"domain"`
+	IncludeSelector string `json:"include_selector,omitempty"`
+	ExcludeSelector string `json:"exclude_selector,omitempty"`
+	TitleSelector   string `json:"title_selector,omitempty"`
+	AuthorSelector  string `json:"author_selector,omitempty"`
+	UserAgent       string `json:"user_agent,omitempty"`
+	CreatedAt       string `json:"created_at"`
+	UpdatedAt       string `json:"updated_at"`
+}
+
+type TranslateRequest struct {
+	TargetLanguage string `json:"target_language" binding:"required,language"`
+}
+
+type LLMTargetStatus struct {
+	Provider  string `json:"provider"`
+	Model     string `json:"model"`
+	State     string `json:"state"`
+	Failures  int    `json:"failures"`
+	OpenedAt  string `json:"opened_at,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+type UsageResponse struct {
+	GroupBy string       `json:"group_by"`
+	Totals  []UsageTotal `json:"totals"`
+}
+
+type UsageTotal struct {
+	Key              string  `json:"key"`
+	Summaries        int     `json:"summaries"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Cost             float64 `json:"cost"`
 }
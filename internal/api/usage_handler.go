@@ -0,0 +1,60 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"anpurnama/summarizer-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) HandleGetUsage(c *gin.Context) {
+	filter := repository.UsageFilter{
+		GroupBy: c.DefaultQuery("group_by", repository.UsageGroupDay),
+		From:    c.Query("from"),
+		To:      c.Query("to"),
+	}
+	if err := filter.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:  ErrCodeInvalidRequest,
+			Error: "group_by must be one of day, model, style or api_key and from/to must be YYYY-MM-DD dates",
+		})
+		return
+	}
+
+	totals, err := h.usageRepo.Aggregate(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch usage: " + err.Error()})
+		return
+	}
+
+	response := UsageResponse{GroupBy: filter.GroupBy, Totals: make([]UsageTotal, len(totals))}
+	for i, t := range totals {
+		response.Totals[i] = UsageTotal{
+			Key:              t.Key,
+			Summaries:        t.Summaries,
+			PromptTokens:     t.PromptTokens,
+			CompletionTokens: t.CompletionTokens,
+			Cost:             t.Cost,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// apiKeyFingerprint identifies the caller's key for usage reports without storing the key itself.
+func apiKeyFingerprint(c *gin.Context) string {
+	key := c.GetHeader("X-API-Key")
+	if key == "" {
+		key = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	}
+	if key == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return "key_" + hex.EncodeToString(sum[:6])
+}
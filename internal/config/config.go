@@ -0,0 +1,205 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"anpurnama/summarizer-backend/internal/service/chunking"
+	"anpurnama/summarizer-backend/internal/service/embedding"
+	"anpurnama/summarizer-backend/internal/service/extractor"
+	"anpurnama/summarizer-backend/internal/service/llm"
+
+	"github.com/joho/godotenv"
+)
+
+const defaultOpenRouterModel = "openai/gpt-4.1-nano"
+
+type Config struct {
+	DatabasePath     string
+	Port             string
+	GinMode          string
+	JobWorkers       int
+	BatchConcurrency int
+	LLMTargets       []LLMTarget
+	Breaker          llm.BreakerSettings
+	Pricing          llm.Pricing
+	ChunkLimits      chunking.Limits
+	Embedding        embedding.Config
+	Extractor        extractor.Config
+}
+
+type LLMTarget struct {
+	Provider llm.Config
+	Model    string
+}
+
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil {
+		log.Printf("No .env file loaded: %v", err)
+	}
+
+	cfg := &Config{
+		DatabasePath:     getEnv("DATABASE_PATH", "./db/database.sqlite"),
+		Port:             getEnv("PORT", "8080"),
+		GinMode:          os.Getenv("GIN_MODE"),
+		JobWorkers:       getEnvInt("JOB_WORKERS", 2),
+		BatchConcurrency: getEnvInt("BATCH_CONCURRENCY", 4),
+		Breaker: llm.BreakerSettings{
+			FailureThreshold: getEnvInt("LLM_BREAKER_FAILURES", 3),
+			Cooldown:         time.Duration(getEnvInt("LLM_BREAKER_COOLDOWN_SECONDS", 30)) * time.Second,
+		},
+	}
+
+	primary := LLMTarget{
+		Provider: llm.Config{
+			Provider: getEnv("LLM_PROVIDER", llm.ProviderOpenRouter),
+			BaseURL:  os.Getenv("LLM_BASE_URL"),
+			APIKey:   os.Getenv("LLM_API_KEY"),
+			Timeout:  time.Duration(getEnvInt("LLM_TIMEOUT_SECONDS", 30)) * time.Second,
+			Retry: llm.RetryPolicy{
+				MaxRetries: getEnvCount("LLM_MAX_RETRIES", 3),
+				BaseDelay:  time.Duration(getEnvInt("LLM_RETRY_BASE_DELAY_MS", 500)) * time.Millisecond,
+				MaxDelay:   time.Duration(getEnvInt("LLM_RETRY_MAX_DELAY_SECONDS", 20)) * time.Second,
+			},
+		},
+		Model: os.Getenv("LLM_MODEL"),
+	}
+
+	if primary.Provider.Provider == llm.ProviderOpenRouter {
+		if primary.Provider.APIKey == "" {
+			primary.Provider.APIKey = os.Getenv("OPENROUTER_API_KEY")
+		}
+		if primary.Model == "" {
+			primary.Model = getEnv("OPENROUTER_MODEL", defaultOpenRouterModel)
+		}
+	}
+	if primary.Model == "" {
+		return nil, fmt.Errorf("LLM_MODEL environment variable is required for the %s provider", primary.Provider.Provider)
+	}
+	cfg.LLMTargets = []LLMTarget{primary}
+
+	fallbacks, err := parseFallbacks(os.Getenv("LLM_FALLBACKS"), primary.Provider)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LLMTargets = append(cfg.LLMTargets, fallbacks...)
+
+	chunkLimits, err := chunking.ParseLimits(os.Getenv("SUMMARIZER_MODEL_CHUNK_LIMITS"), chunking.Config{
+		MaxTokens:   getEnvInt("SUMMARIZER_CHUNK_TOKENS", chunking.DefaultMaxTokens),
+		Concurrency: getEnvInt("SUMMARIZER_CHUNK_CONCURRENCY", chunking.DefaultConcurrency),
+	})
+	if err != nil {
+		return nil, err
+	}
+	cfg.ChunkLimits = chunkLimits
+
+	pricing, err := llm.ParsePricing(os.Getenv("LLM_PRICING"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Pricing = pricing
+
+	cfg.Embedding = embedding.Config{
+		Provider:   getEnv("EMBEDDING_PROVIDER", embedding.ProviderHashing),
+		BaseURL:    os.Getenv("EMBEDDING_BASE_URL"),
+		APIKey:     os.Getenv("EMBEDDING_API_KEY"),
+		Model:      os.Getenv("EMBEDDING_MODEL"),
+		Dimensions: getEnvCount("EMBEDDING_DIMENSIONS", 0),
+		Timeout:    time.Duration(getEnvInt("EMBEDDING_TIMEOUT_SECONDS", 30)) * time.Second,
+	}
+	if cfg.Embedding.Provider == embedding.ProviderOpenAI && cfg.Embedding.APIKey == "" {
+		cfg.Embedding.APIKey = os.Getenv("OPENAI_API_KEY")
+	}
+
+	cfg.Extractor = extractor.Config{
+		Languages:       getEnvList("LANGUAGES", extractor.DefaultLanguages),
+		AllowedHosts:    getEnvList("FETCH_ALLOWLIST", nil),
+		DeniedHosts:     getEnvList("FETCH_DENYLIST", nil),
+		MaxBodyBytes:    int64(getEnvCount("MAX_BODY_BYTES", extractor.DefaultMaxBodyBytes)),
+		UserAgent:       getEnv("FETCH_USER_AGENT", extractor.DefaultUserAgent),
+		RespectRobots:   getEnvBool("RESPECT_ROBOTS_TXT", false),
+		RobotsTTL:       time.Duration(getEnvInt("ROBOTS_TXT_TTL_MINUTES", 60)) * time.Minute,
+		HostConcurrency: getEnvInt("FETCH_HOST_CONCURRENCY", extractor.DefaultHostConcurrency),
+		HostDelay:       time.Duration(getEnvCount("FETCH_HOST_DELAY_MS", 0)) * time.Millisecond,
+		MaxCrawlDelay:   time.Duration(getEnvInt("MAX_CRAWL_DELAY_SECONDS", 30)) * time.Second,
+	}
+
+	return cfg, nil
+}
+
+// parseFallbacks reads "provider:model" pairs separated by commas. Providers other
+// than the primary one take their settings from <PROVIDER>_BASE_URL and <PROVIDER>_API_KEY.
+func parseFallbacks(spec string, primary llm.Config) ([]LLMTarget, error) {
+	var targets []LLMTarget
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		provider, model, ok := strings.Cut(entry, ":")
+		if !ok || provider == "" || model == "" {
+			return nil, fmt.Errorf("invalid LLM fallback %q, expected provider:model", entry)
+		}
+
+		providerConfig := primary
+		if provider != primary.Provider {
+			prefix := strings.ToUpper(provider)
+			providerConfig = llm.Config{
+				Provider: provider,
+				BaseURL:  os.Getenv(prefix + "_BASE_URL"),
+				APIKey:   os.Getenv(prefix + "_API_KEY"),
+				Timeout:  primary.Timeout,
+				Retry:    primary.Retry,
+			}
+		}
+
+		targets = append(targets, LLMTarget{Provider: providerConfig, Model: model})
+	}
+	return targets, nil
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func getEnvCount(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value >= 0 {
+		return value
+	}
+	return fallback
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+func getEnvList(key string, fallback []string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return fallback
+	}
+	return values
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"anpurnama/summarizer-backend/internal/database"
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+)
+
+const domainRuleColumns = `
+	id, domain, include_selector, exclude_selector, title_selector,
+	author_selector, user_agent, created_at, updated_at
+`
+
+type domainRuleRepository struct {
+	db    *database.DB
+	cache struct {
+		byDomain map[string]*DomainRule
+		loaded   bool
+		mu       sync.RWMutex
+	}
+}
+
+func NewDomainRuleRepository(db *database.DB) DomainRuleRepository {
+	return &domainRuleRepository{db: db}
+}
+
+func (r *domainRuleRepository) Create(ctx context.Context, rule *DomainRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO domain_rules (
+			domain, include_selector, exclude_selector, title_selector,
+			author_selector, user_agent
+		) VALUES (?, ?, ?, ?, ?, ?)
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		rule.Domain, rule.IncludeSelector, rule.ExcludeSelector, rule.TitleSelector,
+		rule.AuthorSelector, rule.UserAgent,
+	)
+	if isUniqueViolation(err) {
+		return ErrDomainRuleTaken
+	}
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	r.invalidate()
+
+	created, err := r.GetByID(ctx, int(id))
+	if err != nil {
+		return err
+	}
+	*rule = *created
+
+	return nil
+}
+
+func (r *domainRuleRepository) Update(ctx context.Context, rule *DomainRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE domain_rules
+		SET domain = ?, include_selector = ?, exclude_selector = ?, title_selector = ?,
+			author_selector = ?, user_agent = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		rule.Domain, rule.IncludeSelector, rule.ExcludeSelector, rule.TitleSelector,
+		rule.AuthorSelector, rule.UserAgent, rule.ID,
+	)
+	if isUniqueViolation(err) {
+		return ErrDomainRuleTaken
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrDomainRuleNotFound
+	}
+
+	r.invalidate()
+
+	updated, err := r.GetByID(ctx, rule.ID)
+	if err != nil {
+		return err
+	}
+	*rule = *updated
+
+	return nil
+}
+
+func (r *domainRuleRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM domain_rules WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrDomainRuleNotFound
+	}
+
+	r.invalidate()
+
+	return nil
+}
+
+func (r *domainRuleRepository) invalidate() {
+	r.cache.mu.Lock()
+	defer r.cache.mu.Unlock()
+
+	r.cache.byDomain = nil
+	r.cache.loaded = false
+}
+
+func (r *domainRuleRepository) GetByID(ctx context.Context, id int) (*DomainRule, error) {
+	query := `SELECT ` + domainRuleColumns + ` FROM domain_rules WHERE id = ?`
+	rule := &DomainRule{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(domainRuleFields(rule)...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *domainRuleRepository) List(ctx context.Context) ([]DomainRule, error) {
+	query := `SELECT ` + domainRuleColumns + ` FROM domain_rules ORDER BY domain`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []DomainRule
+	for rows.Next() {
+		var rule DomainRule
+		if err := rows.Scan(domainRuleFields(&rule)...); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// FindForHost runs on every fetch, so it answers from a cache of all rules that
+// is reloaded after any change.
+func (r *domainRuleRepository) FindForHost(ctx context.Context, host string) (*DomainRule, error) {
+	if err := r.load(ctx); err != nil {
+		return nil, err
+	}
+
+	r.cache.mu.RLock()
+	defer r.cache.mu.RUnlock()
+
+	domain := strings.TrimSuffix(strings.ToLower(host), ".")
+	for domain != "" {
+		if rule, ok := r.cache.byDomain[domain]; ok {
+			return rule, nil
+		}
+		_, domain, _ = strings.Cut(domain, ".")
+	}
+	return nil, nil
+}
+
+func (r *domainRuleRepository) load(ctx context.Context) error {
+	r.cache.mu.RLock()
+	loaded := r.cache.loaded
+	r.cache.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	r.cache.mu.Lock()
+	defer r.cache.mu.Unlock()
+	if r.cache.loaded {
+		return nil
+	}
+
+	rules, err := r.List(ctx)
+	if err != nil {
+		return err
+	}
+	r.cache.byDomain = make(map[string]*DomainRule, len(rules))
+	for i := range rules {
+		r.cache.byDomain[rules[i].Domain] = &rules[i]
+	}
+	r.cache.loaded = true
+
+	return nil
+}
+
+func domainRuleFields(d *DomainRule) []any {
+	return []any{
+		&d.ID, &d.Domain, &d.IncludeSelector, &d.ExcludeSelector, &d.TitleSelector,
+		&d.AuthorSelector, &d.UserAgent, &d.CreatedAt, &d.UpdatedAt,
+	}
+}
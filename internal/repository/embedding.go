@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"anpurnama/summarizer-backend/internal/database"
+)
+
+type embeddingRepository struct {
+	db *database.DB
+}
+
+func NewEmbeddingRepository(db *database.DB) EmbeddingRepository {
+	return &embeddingRepository{db: db}
+}
+
+func (r *embeddingRepository) Upsert(ctx context.Context, embeddings []Embedding) error {
+	for i := range embeddings {
+		if err := embeddings[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO history_embeddings (history_id, kind, model, dimensions, vector)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (history_id, kind) DO UPDATE SET
+			model = excluded.model,
+			dimensions = excluded.dimensions,
+			vector = excluded.vector,
+			created_at = CURRENT_TIMESTAMP
+	`
+	for _, e := range embeddings {
+		if _, err := tx.ExecContext(ctx, query,
+			e.HistoryID, e.Kind, e.Model, len(e.Vector), encodeVector(e.Vector),
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *embeddingRepository) ListByModel(ctx context.Context, model string) ([]Embedding, error) {
+	query := `
+		SELECT history_id, kind, model, vector, created_at
+		FROM history_embeddings
+		WHERE model = ?
+	`
+	rows, err := r.db.QueryContext(ctx, query, model)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var embeddings []Embedding
+	for rows.Next() {
+		var e Embedding
+		var vector []byte
+		if err := rows.Scan(&e.HistoryID, &e.Kind, &e.Model, &vector, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if e.Vector, err = decodeVector(vector); err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, e)
+	}
+	return embeddings, rows.Err()
+}
+
+func (r *embeddingRepository) ListUnembedded(ctx context.Context, model string, afterID, limit int) ([]History, error) {
+	query := `
+		SELECT ` + historyColumns + `
+		FROM history h
+		WHERE h.id > ?
+			AND (
+				SELECT COUNT(*) FROM history_embeddings e
+				WHERE e.history_id = h.id AND e.model = ?
+			) < 2
+		ORDER BY h.id
+		LIMIT ?
+	`
+	return queryHistories(ctx, r.db, query, afterID, model, limit)
+}
+
+// encodeVector stores a vector as little-endian float32s for the vector BLOB.
+func encodeVector(vector []float32) []byte {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) ([]float32, error) {
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("invalid vector length %d", len(buf))
+	}
+
+	vector := make([]float32, len(buf)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return vector, nil
+}
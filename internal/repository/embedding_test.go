@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		vector []float32
+	}{
+		{"empty", []float32{}},
+		{"single", []float32{0.5}},
+		{"mixed", []float32{1, -1, 0, 0.25, -0.125, 3.4028235e38, 1.4e-45}},
+		{"special", []float32{float32(math.Inf(1)), float32(math.Inf(-1)), float32(math.Copysign(0, -1))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := encodeVector(tt.vector)
+			if len(encoded) != 4*len(tt.vector) {
+				t.Fatalf("encodeVector produced %d bytes, want %d", len(encoded), 4*len(tt.vector))
+			}
+
+			decoded, err := decodeVector(encoded)
+			if err != nil {
+				t.Fatalf("decodeVector returned error: %v", err)
+			}
+			if len(decoded) != len(tt.vector) {
+				t.Fatalf("decodeVector returned %d values, want %d", len(decoded), len(tt.vector))
+			}
+			for i := range tt.vector {
+				if math.Float32bits(decoded[i]) != math.Float32bits(tt.vector[i]) {
+					t.Errorf("value %d = %v, want %v", i, decoded[i], tt.vector[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeIsLittleEndian(t *testing.T) {
+	encoded := encodeVector([]float32{1})
+	want := []byte{0x00, 0x00, 0x80, 0x3f}
+	if string(encoded) != string(want) {
+		t.Errorf("encodeVector(1) = % x, want % x", encoded, want)
+	}
+}
+
+func TestDecodeRejectsTruncatedVectors(t *testing.T) {
+	for _, size := range []int{1, 2, 3, 5} {
+		if _, err := decodeVector(make([]byte, size)); err == nil {
+			t.Errorf("decodeVector accepted %d bytes", size)
+		}
+	}
+}
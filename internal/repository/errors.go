@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	ErrStyleNotFound  = errors.New("style not found")
+	ErrStyleNameTaken = errors.New("style name already exists")
+	ErrStyleInUse     = errors.New("style is still referenced by history")
+
+	ErrDomainRuleNotFound = errors.New("domain rule not found")
+	ErrDomainRuleTaken    = errors.New("domain already has a rule")
+
+	ErrInvalidSelector    = errors.New("invalid CSS selector")
+	ErrInvalidSearchQuery = errors.New("invalid search query")
+)
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
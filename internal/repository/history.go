@@ -3,10 +3,21 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strings"
 
 	"anpurnama/summarizer-backend/internal/database"
 )
 
+const historyColumns = `
+	h.id, h.url, h.title, h.content, h.summary,
+	h.style_id, h.language, h.chunk_count, h.provider, h.model,
+	h.prompt_tokens, h.completion_tokens, h.cost, h.api_key,
+	h.content_hash, h.prompt_version, h.site_name, h.author,
+	h.excerpt, h.image_url, h.publish_date, h.summary_language,
+	h.translated_from, h.created_at`
+
+const styleColumns = `s.id, s.name, s.description, s.prompt_template, s.default_language, s.created_at`
+
 type historyRepository struct {
 	db *database.DB
 }
@@ -15,25 +26,57 @@ func NewHistoryRepository(db *database.DB) HistoryRepository {
 	return &historyRepository{db: db}
 }
 
+func historyFields(h *History) []any {
+	return []any{
+		&h.ID, &h.URL, &h.Title, &h.Content, &h.Summary,
+		&h.StyleID, &h.Language, &h.ChunkCount, &h.Provider, &h.Model,
+		&h.PromptTokens, &h.CompletionTokens, &h.Cost, &h.APIKey,
+		&h.ContentHash, &h.PromptVersion, &h.SiteName, &h.Author,
+		&h.Excerpt, &h.ImageURL, &h.PublishDate, &h.SummaryLanguage,
+		&h.TranslatedFrom, &h.CreatedAt,
+	}
+}
+
+func nullableStyleFields(s *nullableStyle) []any {
+	return []any{&s.ID, &s.Name, &s.Description, &s.PromptTemplate, &s.DefaultLanguage, &s.CreatedAt}
+}
+
+type nullableStyle struct {
+	ID              sql.NullInt64
+	Name            sql.NullString
+	Description     *string
+	PromptTemplate  sql.NullString
+	DefaultLanguage *string
+	CreatedAt       sql.NullTime
+}
+
+func (s nullableStyle) toStyle() *Style {
+	if !s.ID.Valid {
+		return nil
+	}
+	return &Style{
+		ID:              int(s.ID.Int64),
+		Name:            s.Name.String,
+		Description:     s.Description,
+		PromptTemplate:  s.PromptTemplate.String,
+		DefaultLanguage: s.DefaultLanguage,
+		CreatedAt:       s.CreatedAt.Time,
+	}
+}
+
 func (r *historyRepository) GetWithStyle(ctx context.Context, id int) (*History, error) {
 	query := `
-        SELECT h.id, h.url, h.title, h.content, h.summary,
-            h.style_id, h.language, h.created_at,
-            s.id, s.name, s.description, s.prompt_template, s.created_at
+        SELECT ` + historyColumns + `, ` + styleColumns + `
         FROM history h
         LEFT JOIN summarization_styles s ON h.style_id = s.id
         WHERE h.id = ?
     `
 
 	history := &History{}
-	style := &Style{}
+	style := nullableStyle{}
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&history.ID, &history.URL, &history.Title,
-		&history.Content, &history.Summary, &history.StyleID,
-		&history.Language, &history.CreatedAt,
-		&style.ID, &style.Name, &style.Description,
-		&style.PromptTemplate, &style.CreatedAt,
+		append(historyFields(history), nullableStyleFields(&style)...)...,
 	)
 
 	if err == sql.ErrNoRows {
@@ -43,24 +86,44 @@ func (r *historyRepository) GetWithStyle(ctx context.Context, id int) (*History,
 		return nil, err
 	}
 
-	if style.ID != 0 {
-		history.Style = style
-	}
+	history.Style = style.toStyle()
 
 	return history, nil
 }
 
 func (r *historyRepository) ListWithStyles(ctx context.Context, limit, offset int) ([]History, error) {
 	query := `
-        SELECT h.id, h.url, h.title, h.content, h.summary,
-            h.style_id, h.language, h.created_at,
-            s.id, s.name, s.description, s.prompt_template, s.created_at
+        SELECT ` + historyColumns + `, ` + styleColumns + `
         FROM history h
         LEFT JOIN summarization_styles s ON h.style_id = s.id
         ORDER BY h.created_at DESC
         LIMIT ? OFFSET ?
     `
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	return r.queryWithStyles(ctx, query, limit, offset)
+}
+
+// ListWithStylesByIDs returns the rows with the given ids in one query, in no
+// particular order; ids without a row are skipped.
+func (r *historyRepository) ListWithStylesByIDs(ctx context.Context, ids []int) ([]History, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	query := `
+        SELECT ` + historyColumns + `, ` + styleColumns + `
+        FROM history h
+        LEFT JOIN summarization_styles s ON h.style_id = s.id
+        WHERE h.id IN (` + placeholders + `)
+    `
+	return r.queryWithStyles(ctx, query, args...)
+}
+
+func (r *historyRepository) queryWithStyles(ctx context.Context, query string, args ...any) ([]History, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -69,29 +132,25 @@ func (r *historyRepository) ListWithStyles(ctx context.Context, limit, offset in
 	var histories []History
 	for rows.Next() {
 		h := History{}
-		s := Style{}
-
-		err := rows.Scan(
-			&h.ID, &h.URL, &h.Title, &h.Content,
-			&h.Summary, &h.StyleID, &h.Language, &h.CreatedAt,
-			&s.ID, &s.Name, &s.Description,
-			&s.PromptTemplate, &s.CreatedAt,
-		)
+		s := nullableStyle{}
+
+		err := rows.Scan(append(historyFields(&h), nullableStyleFields(&s)...)...)
 		if err != nil {
 			return nil, err
 		}
 
-		if s.ID != 0 {
-			h.Style = &s
-		}
+		h.Style = s.toStyle()
 
 		histories = append(histories, h)
 	}
-	return histories, nil
+	return histories, rows.Err()
 }
 
 // Update Create method to include validation
 func (r *historyRepository) Create(ctx context.Context, history *History) error {
+	if history.ChunkCount == 0 {
+		history.ChunkCount = 1
+	}
 	if err := history.Validate(); err != nil {
 		return err
 	}
@@ -99,12 +158,21 @@ func (r *historyRepository) Create(ctx context.Context, history *History) error
 	query := `
 		INSERT INTO history (
 			url, title, content, summary, style_id,
-			language
-		) VALUES (?, ?, ?, ?, ?, ?)
+			language, chunk_count, provider, model,
+			prompt_tokens, completion_tokens, cost, api_key,
+			content_hash, prompt_version, site_name, author,
+			excerpt, image_url, publish_date, summary_language,
+			translated_from
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	result, err := r.db.ExecContext(ctx, query,
 		history.URL, history.Title, history.Content,
 		history.Summary, history.StyleID, history.Language,
+		history.ChunkCount, history.Provider, history.Model,
+		history.PromptTokens, history.CompletionTokens, history.Cost, history.APIKey,
+		history.ContentHash, history.PromptVersion, history.SiteName, history.Author,
+		history.Excerpt, history.ImageURL, history.PublishDate, history.SummaryLanguage,
+		history.TranslatedFrom,
 	)
 	if err != nil {
 		return err
@@ -120,17 +188,13 @@ func (r *historyRepository) Create(ctx context.Context, history *History) error
 
 func (r *historyRepository) GetByID(ctx context.Context, id int) (*History, error) {
 	query := `
-        SELECT id, url, title, content, summary, style_id, language, created_at
-        FROM history
-        WHERE id = ?
+        SELECT ` + historyColumns + `
+        FROM history h
+        WHERE h.id = ?
     `
 
 	history := &History{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&history.ID, &history.URL, &history.Title,
-		&history.Content, &history.Summary, &history.StyleID,
-		&history.Language, &history.CreatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(historyFields(history)...)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -140,66 +204,128 @@ func (r *historyRepository) GetByID(ctx context.Context, id int) (*History, erro
 	return history, nil
 }
 
-func (r *historyRepository) List(ctx context.Context, limit, offset int) ([]History, error) {
+func (r *historyRepository) FindCached(ctx context.Context, key SummaryCacheKey) (*History, error) {
 	query := `
-		SELECT id, url, title, content, summary,
-			style_id, language, created_at
-		FROM history
-		ORDER BY created_at DESC
-		LIMIT ? OFFSET ?
+		SELECT ` + historyColumns + `
+		FROM history h
+		WHERE h.content_hash = ? AND h.style_id = ? AND h.prompt_version = ? AND h.model = ?
+		ORDER BY h.id DESC
+		LIMIT 1
 	`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+
+	history := &History{}
+	err := r.db.QueryRowContext(ctx, query, key.ContentHash, key.StyleID, key.PromptVersion, key.Model).Scan(historyFields(history)...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return history, nil
+}
 
-	var histories []History
-	for rows.Next() {
-		var h History
-		err := rows.Scan(
-			&h.ID, &h.URL, &h.Title, &h.Content,
-			&h.Summary, &h.StyleID, &h.Language,
-			&h.CreatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		histories = append(histories, h)
+func (r *historyRepository) FindTranslation(ctx context.Context, historyID int, language string) (*History, error) {
+	query := `
+		SELECT ` + historyColumns + `
+		FROM history h
+		WHERE h.translated_from = ? AND h.summary_language = ?
+		ORDER BY h.id DESC
+		LIMIT 1
+	`
+	histories, err := r.queryHistories(ctx, query, historyID, language)
+	if err != nil || len(histories) == 0 {
+		return nil, err
 	}
-	return histories, nil
+	return &histories[0], nil
 }
 
-func (r *historyRepository) Search(ctx context.Context, query string, limit, offset int) ([]History, error) {
-	sqlQuery := `
-		SELECT id, url, title, content, summary,
-			style_id, language, created_at
-		FROM history
-		WHERE title LIKE ? OR url LIKE ?
-		ORDER BY created_at DESC
+func (r *historyRepository) List(ctx context.Context, limit, offset int) ([]History, error) {
+	query := `
+		SELECT ` + historyColumns + `
+		FROM history h
+		ORDER BY h.created_at DESC
 		LIMIT ? OFFSET ?
 	`
-	searchPattern := "%" + query + "%"
-	rows, err := r.db.QueryContext(ctx, sqlQuery, searchPattern, searchPattern, limit, offset)
+	return r.queryHistories(ctx, query, limit, offset)
+}
+
+// ListAfter pages through history in id order, for backfills that must visit every row.
+func (r *historyRepository) ListAfter(ctx context.Context, afterID, limit int) ([]History, error) {
+	query := `
+		SELECT ` + historyColumns + `
+		FROM history h
+		WHERE h.id > ?
+		ORDER BY h.id
+		LIMIT ?
+	`
+	return r.queryHistories(ctx, query, afterID, limit)
+}
+
+// UpdateLanguage also moves summary_language along when it was only inherited
+// from the old content language, which is the case for summaries that were not
+// translated or generated in a requested language.
+func (r *historyRepository) UpdateLanguage(ctx context.Context, id int, language *string) error {
+	query := `
+		UPDATE history
+		SET language = ?,
+			summary_language = CASE
+				WHEN translated_from IS NULL AND summary_language IS language THEN ?
+				ELSE summary_language
+			END
+		WHERE id = ?
+	`
+	_, err := r.db.ExecContext(ctx, query, language, language, id)
+	return err
+}
+
+func (r *historyRepository) Search(ctx context.Context, search HistorySearch, limit, offset int) ([]SearchHit, error) {
+	match, err := ftsQuery(search)
 	if err != nil {
 		return nil, err
 	}
+
+	query := `
+		SELECT ` + historyColumns + `,
+			snippet(history_fts, -1, char(2), char(3), '…', 24),
+			bm25(history_fts, 10.0, 1.0, 4.0) AS score
+		FROM history_fts
+		JOIN history h ON h.id = history_fts.rowid
+		WHERE history_fts MATCH ?
+		ORDER BY score, h.created_at DESC
+		LIMIT ? OFFSET ?
+	`
+	rows, err := r.db.QueryContext(ctx, query, match, limit, offset)
+	if err != nil {
+		return nil, searchError(err)
+	}
 	defer rows.Close()
 
-	var histories []History
+	var hits []SearchHit
 	for rows.Next() {
-		var h History
-		err := rows.Scan(
-			&h.ID, &h.URL, &h.Title, &h.Content,
-			&h.Summary, &h.StyleID, &h.Language,
-			&h.CreatedAt,
-		)
-		if err != nil {
+		var hit SearchHit
+		if err := rows.Scan(append(historyFields(&hit.History), &hit.Snippet, &hit.Score)...); err != nil {
 			return nil, err
 		}
-		histories = append(histories, h)
+		hit.Snippet = highlightSnippet(hit.Snippet)
+		// bm25 scores are negative with better matches lower; flip them so higher is better.
+		hit.Score = -hit.Score
+		hits = append(hits, hit)
 	}
-	return histories, nil
+	return hits, searchError(rows.Err())
+}
+
+func (r *historyRepository) CountSearch(ctx context.Context, search HistorySearch) (int, error) {
+	match, err := ftsQuery(search)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	query := "SELECT COUNT(*) FROM history_fts WHERE history_fts MATCH ?"
+	if err := r.db.QueryRowContext(ctx, query, match).Scan(&count); err != nil {
+		return 0, searchError(err)
+	}
+	return count, nil
 }
 
 func (r *historyRepository) Count(ctx context.Context) (int, error) {
@@ -211,3 +337,25 @@ func (r *historyRepository) Count(ctx context.Context) (int, error) {
 	}
 	return count, nil
 }
+
+func (r *historyRepository) queryHistories(ctx context.Context, query string, args ...any) ([]History, error) {
+	return queryHistories(ctx, r.db, query, args...)
+}
+
+func queryHistories(ctx context.Context, db *database.DB, query string, args ...any) ([]History, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var histories []History
+	for rows.Next() {
+		var h History
+		if err := rows.Scan(historyFields(&h)...); err != nil {
+			return nil, err
+		}
+		histories = append(histories, h)
+	}
+	return histories, rows.Err()
+}
@@ -8,10 +8,16 @@ type HistoryRepository interface {
 	Create(ctx context.Context, history *History) error
 	GetByID(ctx context.Context, id int) (*History, error)
 	GetWithStyle(ctx context.Context, id int) (*History, error)
+	FindCached(ctx context.Context, key SummaryCacheKey) (*History, error)
+	FindTranslation(ctx context.Context, historyID int, language string) (*History, error)
 	List(ctx context.Context, limit, offset int) ([]History, error)
 	ListWithStyles(ctx context.Context, limit, offset int) ([]History, error)
-	Search(ctx context.Context, query string, limit, offset int) ([]History, error)
+	ListWithStylesByIDs(ctx context.Context, ids []int) ([]History, error)
+	Search(ctx context.Context, search HistorySearch, limit, offset int) ([]SearchHit, error)
+	CountSearch(ctx context.Context, search HistorySearch) (int, error)
 	Count(ctx context.Context) (int, error)
+	ListAfter(ctx context.Context, afterID, limit int) ([]History, error)
+	UpdateLanguage(ctx context.Context, id int, language *string) error
 }
 
 type StyleRepository interface {
@@ -19,4 +25,38 @@ type StyleRepository interface {
 	GetByID(ctx context.Context, id int) (*Style, error)
 	GetByName(ctx context.Context, name string) (*Style, error)
 	List(ctx context.Context) ([]Style, error)
+	Update(ctx context.Context, style *Style) error
+	Delete(ctx context.Context, id int) error
+}
+
+type DomainRuleRepository interface {
+	Create(ctx context.Context, rule *DomainRule) error
+	GetByID(ctx context.Context, id int) (*DomainRule, error)
+	List(ctx context.Context) ([]DomainRule, error)
+	Update(ctx context.Context, rule *DomainRule) error
+	Delete(ctx context.Context, id int) error
+	// FindForHost returns the rule for host or, failing that, its closest parent domain.
+	FindForHost(ctx context.Context, host string) (*DomainRule, error)
+}
+
+type EmbeddingRepository interface {
+	Upsert(ctx context.Context, embeddings []Embedding) error
+	ListByModel(ctx context.Context, model string) ([]Embedding, error)
+	// ListUnembedded returns histories after afterID that lack a complete set of embeddings for model.
+	ListUnembedded(ctx context.Context, model string, afterID, limit int) ([]History, error)
+}
+
+type UsageRepository interface {
+	Aggregate(ctx context.Context, filter UsageFilter) ([]UsageTotal, error)
+}
+
+type JobRepository interface {
+	Create(ctx context.Context, job *Job) error
+	GetByID(ctx context.Context, id int) (*Job, error)
+	ClaimNext(ctx context.Context) (*Job, error)
+	UpdateStage(ctx context.Context, id int, stage string) error
+	Complete(ctx context.Context, id int, historyID int) error
+	Fail(ctx context.Context, id int, message string) error
+	Cancel(ctx context.Context, id int) (bool, error)
+	RequeueRunning(ctx context.Context, maxAttempts int) error
 }
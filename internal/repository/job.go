@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"anpurnama/summarizer-backend/internal/database"
+)
+
+type jobRepository struct {
+	db *database.DB
+}
+
+func NewJobRepository(db *database.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Create(ctx context.Context, job *Job) error {
+	if job.Status == "" {
+		job.Status = JobStatusQueued
+	}
+	if job.Stage == "" {
+		job.Stage = JobStatusQueued
+	}
+	if err := job.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO summarization_jobs (url, style, options, api_key, status, stage)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	result, err := r.db.ExecContext(ctx, query, job.URL, job.Style, job.Options, job.APIKey, job.Status, job.Stage)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	created, err := r.GetByID(ctx, int(id))
+	if err != nil {
+		return err
+	}
+	*job = *created
+	return nil
+}
+
+func (r *jobRepository) GetByID(ctx context.Context, id int) (*Job, error) {
+	query := `
+		SELECT id, url, style, options, api_key, status, stage, attempts, error, history_id,
+			created_at, updated_at, started_at, finished_at
+		FROM summarization_jobs
+		WHERE id = ?
+	`
+
+	job := &Job{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&job.ID, &job.URL, &job.Style, &job.Options, &job.APIKey, &job.Status, &job.Stage,
+		&job.Attempts, &job.Error, &job.HistoryID,
+		&job.CreatedAt, &job.UpdatedAt, &job.StartedAt, &job.FinishedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *jobRepository) ClaimNext(ctx context.Context) (*Job, error) {
+	query := `
+		UPDATE summarization_jobs
+		SET status = 'running', stage = 'queued', attempts = attempts + 1, error = NULL,
+			started_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM summarization_jobs
+			WHERE status = 'queued'
+			ORDER BY id
+			LIMIT 1
+		)
+		RETURNING id
+	`
+
+	var id int
+	err := r.db.QueryRowContext(ctx, query).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *jobRepository) UpdateStage(ctx context.Context, id int, stage string) error {
+	query := `
+		UPDATE summarization_jobs
+		SET stage = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'running'
+	`
+	_, err := r.db.ExecContext(ctx, query, stage, id)
+	return err
+}
+
+func (r *jobRepository) Complete(ctx context.Context, id int, historyID int) error {
+	query := `
+		UPDATE summarization_jobs
+		SET status = 'completed', stage = 'completed', history_id = ?,
+			updated_at = CURRENT_TIMESTAMP, finished_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'running'
+	`
+	_, err := r.db.ExecContext(ctx, query, historyID, id)
+	return err
+}
+
+func (r *jobRepository) Fail(ctx context.Context, id int, message string) error {
+	query := `
+		UPDATE summarization_jobs
+		SET status = 'failed', error = ?,
+			updated_at = CURRENT_TIMESTAMP, finished_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'running'
+	`
+	_, err := r.db.ExecContext(ctx, query, message, id)
+	return err
+}
+
+func (r *jobRepository) Cancel(ctx context.Context, id int) (bool, error) {
+	query := `
+		UPDATE summarization_jobs
+		SET status = 'cancelled',
+			updated_at = CURRENT_TIMESTAMP, finished_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status IN ('queued', 'running')
+	`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *jobRepository) RequeueRunning(ctx context.Context, maxAttempts int) error {
+	query := `
+		UPDATE summarization_jobs
+		SET status = CASE WHEN attempts < ? THEN 'queued' ELSE 'failed' END,
+			stage = CASE WHEN attempts < ? THEN 'queued' ELSE stage END,
+			error = CASE WHEN attempts < ? THEN error ELSE 'interrupted too many times' END,
+			finished_at = CASE WHEN attempts < ? THEN NULL ELSE CURRENT_TIMESTAMP END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE status = 'running'
+	`
+	_, err := r.db.ExecContext(ctx, query, maxAttempts, maxAttempts, maxAttempts, maxAttempts)
+	return err
+}
@@ -1,26 +1,47 @@
 package repository
 
 import (
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/andybalholm/cascadia"
 	"github.com/go-playground/validator/v10"
 )
 
 type History struct {
-	ID        int       `validate:"-"`
-	URL       string    `validate:"required,url"`
-	Title     *string   `validate:"omitempty,min=1"`
-	Content   string    `validate:"required"`
-	Summary   string    `validate:"required"`
-	StyleID   *int      `validate:"required"`
-	Language  *string   `validate:"omitempty,iso639_1"`
-	CreatedAt time.Time `validate:"-"`
-	Style     *Style    `validate:"-"`
+	ID               int        `validate:"-"`
+	URL              string     `validate:"required,source"`
+	Title            *string    `validate:"omitempty,min=1"`
+	Content          string     `validate:"required"`
+	Summary          string     `validate:"required"`
+	StyleID          *int       `validate:"required"`
+	Language         *string    `validate:"omitempty,iso639_1"`
+	ChunkCount       int        `validate:"min=1"`
+	Provider         *string    `validate:"omitempty,min=1"`
+	Model            *string    `validate:"omitempty,min=1"`
+	PromptTokens     *int       `validate:"omitempty,min=0"`
+	CompletionTokens *int       `validate:"omitempty,min=0"`
+	Cost             *float64   `validate:"omitempty,min=0"`
+	APIKey           *string    `validate:"omitempty,min=1"`
+	ContentHash      *string    `validate:"omitempty,hexadecimal"`
+	PromptVersion    *string    `validate:"omitempty,hexadecimal"`
+	SiteName         *string    `validate:"omitempty,min=1"`
+	Author           *string    `validate:"omitempty,min=1"`
+	Excerpt          *string    `validate:"omitempty,min=1"`
+	ImageURL         *string    `validate:"omitempty,url"`
+	PublishDate      *time.Time `validate:"-"`
+	SummaryLanguage  *string    `validate:"omitempty,iso639_1"`
+	TranslatedFrom   *int       `validate:"-"`
+	CreatedAt        time.Time  `validate:"-"`
+	Style            *Style     `validate:"-"`
 }
 
 func (h *History) Validate() error {
 	validate := validator.New()
 	validate.RegisterValidation("iso639_1", validateISO639_1)
+	validate.RegisterValidation("source", validateSource)
 
 	return validate.Struct(h)
 }
@@ -47,15 +68,160 @@ func validateISO639_1(fl validator.FieldLevel) bool {
 	return true
 }
 
+// validateSource accepts an http(s) URL or the synthetic "text:" and "file:"
+// identifiers given to summaries of pasted text and uploaded files
+func validateSource(fl validator.FieldLevel) bool {
+	source := fl.Field().String()
+	if strings.HasPrefix(source, "text:") || strings.HasPrefix(source, "file:") {
+		return len(source) > len("text:")
+	}
+
+	u, err := url.ParseRequestURI(source)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
 type Style struct {
-	ID             int       `validate:"required"`
-	Name           string    `validate:"required,min=1"`
-	Description    *string   `validate:"omitempty,min=1"`
-	PromptTemplate string    `validate:"required,min=1"`
-	CreatedAt      time.Time `validate:"required"`
+	ID              int       `validate:"-"`
+	Name            string    `validate:"required,min=1,max=100"`
+	Description     *string   `validate:"omitempty,min=1"`
+	PromptTemplate  string    `validate:"required,min=1"`
+	DefaultLanguage *string   `validate:"omitempty,iso639_1"`
+	CreatedAt       time.Time `validate:"-"`
 }
 
 func (s *Style) Validate() error {
 	validate := validator.New()
+	validate.RegisterValidation("iso639_1", validateISO639_1)
 	return validate.Struct(s)
 }
+
+// DomainRule tunes extraction for a domain and its subdomains. Each selector is
+// a CSS selector group, so several selectors can be joined with commas.
+type DomainRule struct {
+	ID              int       `validate:"-"`
+	Domain          string    `validate:"required,hostname_rfc1123,max=253"`
+	IncludeSelector *string   `validate:"omitempty,min=1"`
+	ExcludeSelector *string   `validate:"omitempty,min=1"`
+	TitleSelector   *string   `validate:"omitempty,min=1"`
+	AuthorSelector  *string   `validate:"omitempty,min=1"`
+	UserAgent       *string   `validate:"omitempty,min=1,max=512"`
+	CreatedAt       time.Time `validate:"-"`
+	UpdatedAt       time.Time `validate:"-"`
+}
+
+func (d *DomainRule) Validate() error {
+	validate := validator.New()
+	if err := validate.Struct(d); err != nil {
+		return err
+	}
+
+	for _, selector := range []*string{d.IncludeSelector, d.ExcludeSelector, d.TitleSelector, d.AuthorSelector} {
+		if selector == nil {
+			continue
+		}
+		if _, err := cascadia.Compile(*selector); err != nil {
+			return fmt.Errorf("%w %q: %v", ErrInvalidSelector, *selector, err)
+		}
+	}
+	return nil
+}
+
+type SummaryCacheKey struct {
+	ContentHash   string
+	StyleID       int
+	PromptVersion string
+	Model         string
+}
+
+// HistorySearch is a full-text query over title, content and summary. Query accepts
+// bare terms, "quoted phrases", prefix* terms, column:term filters and AND/OR/NOT.
+// Columns, when set, restricts the whole query to those columns.
+type HistorySearch struct {
+	Query   string
+	Columns []string
+}
+
+type SearchHit struct {
+	History
+	Snippet string
+	Score   float64
+}
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+type Job struct {
+	ID         int        `validate:"-"`
+	URL        string     `validate:"required,url"`
+	Style      *string    `validate:"omitempty,min=1"`
+	Options    *string    `validate:"omitempty,json"`
+	APIKey     *string    `validate:"omitempty,min=1"`
+	Status     string     `validate:"required,oneof=queued running completed failed cancelled"`
+	Stage      string     `validate:"required"`
+	Attempts   int        `validate:"min=0"`
+	Error      *string    `validate:"-"`
+	HistoryID  *int       `validate:"-"`
+	CreatedAt  time.Time  `validate:"-"`
+	UpdatedAt  time.Time  `validate:"-"`
+	StartedAt  *time.Time `validate:"-"`
+	FinishedAt *time.Time `validate:"-"`
+}
+
+func (j *Job) Validate() error {
+	validate := validator.New()
+	return validate.Struct(j)
+}
+
+func (j *Job) Finished() bool {
+	return j.Status == JobStatusCompleted || j.Status == JobStatusFailed || j.Status == JobStatusCancelled
+}
+
+const (
+	UsageGroupDay    = "day"
+	UsageGroupModel  = "model"
+	UsageGroupStyle  = "style"
+	UsageGroupAPIKey = "api_key"
+)
+
+type UsageFilter struct {
+	GroupBy string `validate:"required,oneof=day model style api_key"`
+	// From and To are inclusive calendar days in YYYY-MM-DD form.
+	From string `validate:"omitempty,datetime=2006-01-02"`
+	To   string `validate:"omitempty,datetime=2006-01-02"`
+}
+
+func (f *UsageFilter) Validate() error {
+	validate := validator.New()
+	return validate.Struct(f)
+}
+
+type UsageTotal struct {
+	Key              string
+	Summaries        int
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64
+}
+
+const (
+	EmbeddingKindSummary = "summary"
+	EmbeddingKindContent = "content"
+)
+
+type Embedding struct {
+	HistoryID int       `validate:"required"`
+	Kind      string    `validate:"required,oneof=summary content"`
+	Model     string    `validate:"required"`
+	Vector    []float32 `validate:"required,min=1"`
+	CreatedAt time.Time `validate:"-"`
+}
+
+func (e *Embedding) Validate() error {
+	validate := validator.New()
+	return validate.Struct(e)
+}
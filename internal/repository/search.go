@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var searchColumns = map[string]bool{"title": true, "content": true, "summary": true}
+
+type searchTerm struct {
+	text     string
+	operator bool
+}
+
+// ftsQuery translates a HistorySearch into an FTS5 MATCH expression. Every term is
+// quoted so that punctuation in user input cannot break the FTS5 query syntax.
+func ftsQuery(search HistorySearch) (string, error) {
+	var terms []searchTerm
+	for _, token := range tokenizeSearch(search.Query) {
+		switch token {
+		case "AND", "OR", "NOT":
+			terms = append(terms, searchTerm{text: token, operator: true})
+			continue
+		}
+
+		column := ""
+		if name, rest, ok := strings.Cut(token, ":"); ok && searchColumns[strings.ToLower(name)] && rest != "" {
+			column, token = strings.ToLower(name), rest
+		}
+
+		term := ftsTerm(token)
+		if term == "" {
+			continue
+		}
+		if column != "" {
+			term = column + " : " + term
+		}
+		terms = append(terms, searchTerm{text: term})
+	}
+
+	var parts []string
+	pendingOperator := ""
+	for _, term := range terms {
+		if term.operator {
+			// Dropping a dangling operator would silently change the query, turning
+			// "NOT spam" into a search for exactly what was meant to be excluded.
+			if len(parts) == 0 || pendingOperator != "" {
+				return "", fmt.Errorf("%w: %s has no term on its left", ErrInvalidSearchQuery, term.text)
+			}
+			pendingOperator = term.text
+			continue
+		}
+		if pendingOperator != "" {
+			parts = append(parts, pendingOperator)
+			pendingOperator = ""
+		}
+		parts = append(parts, term.text)
+	}
+	if pendingOperator != "" {
+		return "", fmt.Errorf("%w: %s has no term on its right", ErrInvalidSearchQuery, pendingOperator)
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("%w: no search terms", ErrInvalidSearchQuery)
+	}
+	match := strings.Join(parts, " ")
+
+	if len(search.Columns) > 0 {
+		columns := make([]string, len(search.Columns))
+		for i, column := range search.Columns {
+			column = strings.ToLower(strings.TrimSpace(column))
+			if !searchColumns[column] {
+				return "", fmt.Errorf("%w: unknown column %q", ErrInvalidSearchQuery, column)
+			}
+			columns[i] = column
+		}
+		match = "{" + strings.Join(columns, " ") + "} : (" + match + ")"
+	}
+	return match, nil
+}
+
+// tokenizeSearch splits a query on whitespace while keeping "quoted phrases",
+// including a column prefix such as title:"a phrase", together as one token.
+func tokenizeSearch(query string) []string {
+	var tokens []string
+	var current strings.Builder
+	inPhrase := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			inPhrase = !inPhrase
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !inPhrase:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// ftsTerm quotes a single term or phrase, keeping a trailing * as a prefix query.
+func ftsTerm(token string) string {
+	prefix := strings.HasSuffix(token, "*")
+	token = strings.TrimSuffix(token, "*")
+	token = strings.TrimSpace(strings.ReplaceAll(token, `"`, " "))
+	if token == "" {
+		return ""
+	}
+
+	term := `"` + token + `"`
+	if prefix {
+		term += "*"
+	}
+	return term
+}
+
+// Search has snippet() delimit matches with char(2) and char(3) rather than markup,
+// since the surrounding text is page content scraped from arbitrary sites.
+const (
+	snippetMatchStart = "\x02"
+	snippetMatchEnd   = "\x03"
+)
+
+// highlightSnippet HTML-escapes a snippet and only then wraps its matches in
+// <mark> tags, so the tags are the only markup in the result.
+func highlightSnippet(snippet string) string {
+	return strings.NewReplacer(
+		snippetMatchStart, "<mark>",
+		snippetMatchEnd, "</mark>",
+	).Replace(html.EscapeString(snippet))
+}
+
+func searchError(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && strings.Contains(sqliteErr.Error(), "fts5") {
+		return fmt.Errorf("%w: %v", ErrInvalidSearchQuery, err)
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTokenizeSearch(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", nil},
+		{"  go   sqlite ", []string{"go", "sqlite"}},
+		{`"full text" search`, []string{`"full text"`, "search"}},
+		{`title:"a phrase" body`, []string{`title:"a phrase"`, "body"}},
+		{"summ* AND go", []string{"summ*", "AND", "go"}},
+		{`"unterminated phrase`, []string{`"unterminated phrase`}},
+	}
+
+	for _, tt := range tests {
+		if got := tokenizeSearch(tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("tokenizeSearch(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		search HistorySearch
+		want   string
+	}{
+		{"terms", HistorySearch{Query: "go sqlite"}, `"go" "sqlite"`},
+		{"phrase", HistorySearch{Query: `"full text" search`}, `"full text" "search"`},
+		{"prefix", HistorySearch{Query: "summ*"}, `"summ"*`},
+		{"punctuation", HistorySearch{Query: `c++ (draft) "`}, `"c++" "(draft)"`},
+		{"column filter", HistorySearch{Query: `Title:"a phrase" body`}, `title : "a phrase" "body"`},
+		{"column prefix", HistorySearch{Query: "summary:summ*"}, `summary : "summ"*`},
+		{"unknown column is a term", HistorySearch{Query: "url:example"}, `"url:example"`},
+		{"operators", HistorySearch{Query: "go AND sqlite OR postgres NOT mysql"}, `"go" AND "sqlite" OR "postgres" NOT "mysql"`},
+		{"lowercase operators are terms", HistorySearch{Query: "cats and dogs"}, `"cats" "and" "dogs"`},
+		{"columns", HistorySearch{Query: "go OR rust", Columns: []string{" Title", "summary"}}, `{title summary} : ("go" OR "rust")`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ftsQuery(tt.search)
+			if err != nil {
+				t.Fatalf("ftsQuery returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ftsQuery(%q) = %s, want %s", tt.search.Query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFTSQueryRejectsInvalidQueries(t *testing.T) {
+	tests := []struct {
+		name   string
+		search HistorySearch
+	}{
+		{"empty", HistorySearch{Query: "   "}},
+		{"only quotes", HistorySearch{Query: `"" *`}},
+		{"leading NOT", HistorySearch{Query: "NOT spam"}},
+		{"leading OR", HistorySearch{Query: "OR go"}},
+		{"trailing AND", HistorySearch{Query: "go AND"}},
+		{"trailing OR", HistorySearch{Query: "go OR"}},
+		{"consecutive operators", HistorySearch{Query: "go AND NOT spam"}},
+		{"operator before an empty term", HistorySearch{Query: `go NOT ""`}},
+		{"unknown column", HistorySearch{Query: "go", Columns: []string{"url"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ftsQuery(tt.search); !errors.Is(err, ErrInvalidSearchQuery) {
+				t.Errorf("ftsQuery(%q) = %s, %v, want ErrInvalidSearchQuery", tt.search.Query, got, err)
+			}
+		})
+	}
+}
+
+func TestHighlightSnippetEscapesContent(t *testing.T) {
+	snippet := "…<img src=x onerror=alert(1)> and \x02fish\x03 & \x02chips\x03…"
+	want := "…&lt;img src=x onerror=alert(1)&gt; and <mark>fish</mark> &amp; <mark>chips</mark>…"
+	if got := highlightSnippet(snippet); got != want {
+		t.Errorf("highlightSnippet = %q, want %q", got, want)
+	}
+}
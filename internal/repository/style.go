@@ -12,7 +12,10 @@ type styleRepository struct {
 	cache struct {
 		byID   map[int]*Style
 		byName map[string]*Style
-		mu     sync.RWMutex
+		// version changes on every invalidation so that a fill which read the
+		// database before a write cannot put the old row back.
+		version uint64
+		mu      sync.RWMutex
 	}
 }
 
@@ -20,9 +23,10 @@ func NewStyleRepository(db *database.DB) StyleRepository {
 	return &styleRepository{
 		db: db,
 		cache: struct {
-			byID   map[int]*Style
-			byName map[string]*Style
-			mu     sync.RWMutex
+			byID    map[int]*Style
+			byName  map[string]*Style
+			version uint64
+			mu      sync.RWMutex
 		}{
 			byID:   make(map[int]*Style),
 			byName: make(map[string]*Style),
@@ -37,12 +41,15 @@ func (r *styleRepository) Create(ctx context.Context, style *Style) error {
 
 	query := `
 		INSERT INTO summarization_styles (
-			name, description, prompt_template
-		) VALUES (?, ?, ?)
+			name, description, prompt_template, default_language
+		) VALUES (?, ?, ?, ?)
 	`
 	result, err := r.db.ExecContext(ctx, query,
-		style.Name, style.Description, style.PromptTemplate,
+		style.Name, style.Description, style.PromptTemplate, style.DefaultLanguage,
 	)
+	if isUniqueViolation(err) {
+		return ErrStyleNameTaken
+	}
 	if err != nil {
 		return err
 	}
@@ -51,15 +58,117 @@ func (r *styleRepository) Create(ctx context.Context, style *Style) error {
 	if err != nil {
 		return err
 	}
-	style.ID = int(id)
 
-	// Update cache
+	created, err := r.GetByID(ctx, int(id))
+	if err != nil {
+		return err
+	}
+	*style = *created
+
+	return nil
+}
+
+func (r *styleRepository) Update(ctx context.Context, style *Style) error {
+	if err := style.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE summarization_styles
+		SET name = ?, description = ?, prompt_template = ?, default_language = ?
+		WHERE id = ?
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		style.Name, style.Description, style.PromptTemplate, style.DefaultLanguage, style.ID,
+	)
+	if isUniqueViolation(err) {
+		return ErrStyleNameTaken
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrStyleNotFound
+	}
+
+	r.invalidate(style.ID)
+
+	updated, err := r.GetByID(ctx, style.ID)
+	if err != nil {
+		return err
+	}
+	*style = *updated
+
+	return nil
+}
+
+func (r *styleRepository) Delete(ctx context.Context, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var references int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM history WHERE style_id = ?", id).Scan(&references)
+	if err != nil {
+		return err
+	}
+	if references > 0 {
+		return ErrStyleInUse
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM summarization_styles WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrStyleNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	r.invalidate(id)
+
+	return nil
+}
+
+func (r *styleRepository) invalidate(id int) {
 	r.cache.mu.Lock()
+	defer r.cache.mu.Unlock()
+
+	r.cache.version++
+	delete(r.cache.byID, id)
+	for name, style := range r.cache.byName {
+		if style.ID == id {
+			delete(r.cache.byName, name)
+		}
+	}
+}
+
+// store caches a style read while the cache was at version, unless an
+// invalidation has happened since.
+func (r *styleRepository) store(version uint64, style *Style) {
+	r.cache.mu.Lock()
+	defer r.cache.mu.Unlock()
+
+	if r.cache.version != version {
+		return
+	}
 	r.cache.byID[style.ID] = style
 	r.cache.byName[style.Name] = style
-	r.cache.mu.Unlock()
-
-	return nil
 }
 
 func (r *styleRepository) GetByID(ctx context.Context, id int) (*Style, error) {
@@ -69,16 +178,17 @@ func (r *styleRepository) GetByID(ctx context.Context, id int) (*Style, error) {
 		r.cache.mu.RUnlock()
 		return style, nil
 	}
+	version := r.cache.version
 	r.cache.mu.RUnlock()
 
 	query := `
-		SELECT id, name, description, prompt_template, created_at
+		SELECT id, name, description, prompt_template, default_language, created_at
 		FROM summarization_styles WHERE id = ?
 	`
 	style := &Style{}
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&style.ID, &style.Name, &style.Description,
-		&style.PromptTemplate, &style.CreatedAt,
+		&style.PromptTemplate, &style.DefaultLanguage, &style.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -87,11 +197,7 @@ func (r *styleRepository) GetByID(ctx context.Context, id int) (*Style, error) {
 		return nil, err
 	}
 
-	// Update cache
-	r.cache.mu.Lock()
-	r.cache.byID[style.ID] = style
-	r.cache.byName[style.Name] = style
-	r.cache.mu.Unlock()
+	r.store(version, style)
 
 	return style, nil
 }
@@ -103,16 +209,17 @@ func (r *styleRepository) GetByName(ctx context.Context, name string) (*Style, e
 		r.cache.mu.RUnlock()
 		return style, nil
 	}
+	version := r.cache.version
 	r.cache.mu.RUnlock()
 
 	query := `
-		SELECT id, name, description, prompt_template, created_at
+		SELECT id, name, description, prompt_template, default_language, created_at
 		FROM summarization_styles WHERE name = ?
 	`
 	style := &Style{}
 	err := r.db.QueryRowContext(ctx, query, name).Scan(
 		&style.ID, &style.Name, &style.Description,
-		&style.PromptTemplate, &style.CreatedAt,
+		&style.PromptTemplate, &style.DefaultLanguage, &style.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -121,21 +228,21 @@ func (r *styleRepository) GetByName(ctx context.Context, name string) (*Style, e
 		return nil, err
 	}
 
-	// Update cache
-	r.cache.mu.Lock()
-	r.cache.byID[style.ID] = style
-	r.cache.byName[style.Name] = style
-	r.cache.mu.Unlock()
+	r.store(version, style)
 
 	return style, nil
 }
 
 func (r *styleRepository) List(ctx context.Context) ([]Style, error) {
 	query := `
-		SELECT id, name, description, prompt_template, created_at
+		SELECT id, name, description, prompt_template, default_language, created_at
 		FROM summarization_styles
 		ORDER BY created_at DESC
 	`
+	r.cache.mu.RLock()
+	version := r.cache.version
+	r.cache.mu.RUnlock()
+
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -147,18 +254,13 @@ func (r *styleRepository) List(ctx context.Context) ([]Style, error) {
 		var s Style
 		err := rows.Scan(
 			&s.ID, &s.Name, &s.Description,
-			&s.PromptTemplate, &s.CreatedAt,
+			&s.PromptTemplate, &s.DefaultLanguage, &s.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
 		styles = append(styles, s)
-
-		// Update cache
-		r.cache.mu.Lock()
-		r.cache.byID[s.ID] = &s
-		r.cache.byName[s.Name] = &s
-		r.cache.mu.Unlock()
+		r.store(version, &s)
 	}
 	return styles, nil
 }
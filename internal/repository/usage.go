@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+
+	"anpurnama/summarizer-backend/internal/database"
+)
+
+var usageGroupKeys = map[string]string{
+	UsageGroupDay:    "date(h.created_at)",
+	UsageGroupModel:  "COALESCE(h.model, 'unknown')",
+	UsageGroupStyle:  "COALESCE(s.name, 'unknown')",
+	UsageGroupAPIKey: "COALESCE(h.api_key, 'anonymous')",
+}
+
+type usageRepository struct {
+	db *database.DB
+}
+
+func NewUsageRepository(db *database.DB) UsageRepository {
+	return &usageRepository{db: db}
+}
+
+func (r *usageRepository) Aggregate(ctx context.Context, filter UsageFilter) ([]UsageTotal, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	order := "total_cost DESC, group_key"
+	if filter.GroupBy == UsageGroupDay {
+		order = "group_key DESC"
+	}
+
+	query := `
+		SELECT ` + usageGroupKeys[filter.GroupBy] + ` AS group_key,
+			COUNT(*),
+			COALESCE(SUM(h.prompt_tokens), 0),
+			COALESCE(SUM(h.completion_tokens), 0),
+			COALESCE(SUM(h.cost), 0) AS total_cost
+		FROM history h
+		LEFT JOIN summarization_styles s ON h.style_id = s.id
+		WHERE (? = '' OR date(h.created_at) >= ?)
+			AND (? = '' OR date(h.created_at) <= ?)
+		GROUP BY group_key
+		ORDER BY ` + order
+
+	rows, err := r.db.QueryContext(ctx, query, filter.From, filter.From, filter.To, filter.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []UsageTotal
+	for rows.Next() {
+		var t UsageTotal
+		if err := rows.Scan(&t.Key, &t.Summaries, &t.PromptTokens, &t.CompletionTokens, &t.Cost); err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+type BatchItemResult struct {
+	Input  SummarizeInput
+	Result *SummarizeResult
+	Err    error
+}
+
+func (p *Pipeline) RunBatch(ctx context.Context, inputs []SummarizeInput, concurrency int) []BatchItemResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]BatchItemResult, len(inputs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, input := range inputs {
+		results[i].Input = input
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, input SummarizeInput) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			results[i].Result, results[i].Err = p.Run(ctx, input, Progress{})
+		}(i, input)
+	}
+
+	wg.Wait()
+	return results
+}
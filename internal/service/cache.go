@@ -0,0 +1,28 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"anpurnama/summarizer-backend/internal/repository"
+)
+
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// promptVersion fingerprints everything besides the article that shapes the prompt,
+// so editing a style or changing request options never serves a stale summary.
+func promptVersion(style *repository.Style, options SummarizeOptions) string {
+	fingerprint, _ := json.Marshal(struct {
+		Template       string            `json:"template"`
+		MaxWords       int               `json:"max_words"`
+		Params         map[string]string `json:"params"`
+		TargetLanguage string            `json:"target_language,omitempty"`
+	}{style.PromptTemplate, options.MaxWords, options.Params, options.TargetLanguage})
+
+	sum := sha256.Sum256(fingerprint)
+	return hex.EncodeToString(sum[:8])
+}
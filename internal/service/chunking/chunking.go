@@ -0,0 +1,228 @@
+package chunking
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	DefaultMaxTokens   = 24000
+	DefaultConcurrency = 3
+	charsPerToken      = 4
+)
+
+var (
+	paragraphBreak = regexp.MustCompile(`\n\s*\n`)
+	sentenceEnd    = regexp.MustCompile(`([.!?])\s+`)
+)
+
+type Config struct {
+	MaxTokens   int
+	Concurrency int
+}
+
+type Limits struct {
+	Default  Config
+	PerModel map[string]Config
+}
+
+func (l Limits) For(model string) Config {
+	if config, ok := l.PerModel[model]; ok {
+		return config
+	}
+	return l.Default
+}
+
+// Smallest picks the tightest limits among models, so chunks fit whichever of
+// them ends up serving the request.
+func (l Limits) Smallest(models []string) Config {
+	if len(models) == 0 {
+		return l.Default
+	}
+	smallest := l.For(models[0])
+	for _, model := range models[1:] {
+		config := l.For(model)
+		smallest.MaxTokens = min(smallest.MaxTokens, config.MaxTokens)
+		smallest.Concurrency = min(smallest.Concurrency, config.Concurrency)
+	}
+	return smallest
+}
+
+// ParseLimits reads "model=maxTokens:concurrency" pairs separated by commas.
+func ParseLimits(spec string, defaults Config) (Limits, error) {
+	limits := Limits{Default: defaults, PerModel: make(map[string]Config)}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		model, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return Limits{}, fmt.Errorf("invalid chunk limit %q", entry)
+		}
+
+		config := defaults
+		tokens, concurrency, hasConcurrency := strings.Cut(value, ":")
+		maxTokens, err := strconv.Atoi(tokens)
+		if err != nil || maxTokens <= 0 {
+			return Limits{}, fmt.Errorf("invalid max tokens in chunk limit %q", entry)
+		}
+		config.MaxTokens = maxTokens
+
+		if hasConcurrency {
+			c, err := strconv.Atoi(concurrency)
+			if err != nil || c <= 0 {
+				return Limits{}, fmt.Errorf("invalid concurrency in chunk limit %q", entry)
+			}
+			config.Concurrency = c
+		}
+
+		limits.PerModel[strings.TrimSpace(model)] = config
+	}
+	return limits, nil
+}
+
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+func Split(text string, maxTokens int) []string {
+	if EstimateTokens(text) <= maxTokens {
+		return []string{text}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+
+	for _, piece := range pieces(text, maxTokens) {
+		if current.Len() > 0 && !fits(current.String(), "\n\n", piece, maxTokens) {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(piece)
+	}
+	flush()
+
+	return chunks
+}
+
+// SplitAt splits text like Split but breaks at the given offsets, such as
+// chapter starts, wherever it can: each chunk holds whole sections, and only a
+// section too large for one chunk is split by Split.
+func SplitAt(text string, offsets []int, maxTokens int) []string {
+	if len(offsets) == 0 || EstimateTokens(text) <= maxTokens {
+		return Split(text, maxTokens)
+	}
+
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, section := range sections(text, offsets) {
+		if EstimateTokens(section) > maxTokens {
+			flush()
+			chunks = append(chunks, Split(section, maxTokens)...)
+			continue
+		}
+		if current.Len() > 0 && !fits(current.String(), "\n\n", section, maxTokens) {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(section)
+	}
+	flush()
+
+	return chunks
+}
+
+func sections(text string, offsets []int) []string {
+	var result []string
+	start := 0
+	add := func(end int) {
+		if section := strings.TrimSpace(text[start:end]); section != "" {
+			result = append(result, section)
+		}
+		start = end
+	}
+	for _, offset := range offsets {
+		if offset > start && offset < len(text) && utf8.RuneStart(text[offset]) {
+			add(offset)
+		}
+	}
+	add(len(text))
+	return result
+}
+
+func pieces(text string, maxTokens int) []string {
+	var result []string
+	for _, paragraph := range paragraphBreak.Split(text, -1) {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		if EstimateTokens(paragraph) <= maxTokens {
+			result = append(result, paragraph)
+			continue
+		}
+		result = append(result, splitParagraph(paragraph, maxTokens)...)
+	}
+	return result
+}
+
+func splitParagraph(paragraph string, maxTokens int) []string {
+	sentences := strings.Split(sentenceEnd.ReplaceAllString(paragraph, "$1\n"), "\n")
+	maxChars := maxTokens * charsPerToken
+
+	var result []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			result = append(result, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	for _, sentence := range sentences {
+		if utf8.RuneCountInString(sentence) > maxChars {
+			flush()
+			for utf8.RuneCountInString(sentence) > maxChars {
+				runes := []rune(sentence)
+				result = append(result, string(runes[:maxChars]))
+				sentence = string(runes[maxChars:])
+			}
+		}
+
+		if current.Len() > 0 && !fits(current.String(), " ", sentence, maxTokens) {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString(" ")
+		}
+		current.WriteString(sentence)
+	}
+	flush()
+	return result
+}
+
+// fits reports whether current joined to next by sep stays within maxTokens.
+func fits(current, sep, next string, maxTokens int) bool {
+	return utf8.RuneCountInString(current)+len(sep)+utf8.RuneCountInString(next) <= maxTokens*charsPerToken
+}
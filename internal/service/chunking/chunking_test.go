@@ -0,0 +1,190 @@
+package chunking
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// checkChunks verifies that every chunk fits maxTokens, is valid UTF-8 and that
+// together the chunks keep every word of text in order.
+func checkChunks(t *testing.T, text string, chunks []string, maxTokens int) {
+	t.Helper()
+	for i, chunk := range chunks {
+		if !utf8.ValidString(chunk) {
+			t.Errorf("chunk %d is not valid UTF-8: %q", i, chunk)
+		}
+		if tokens := EstimateTokens(chunk); tokens > maxTokens {
+			t.Errorf("chunk %d has %d tokens, want at most %d", i, tokens, maxTokens)
+		}
+		if strings.TrimSpace(chunk) == "" {
+			t.Errorf("chunk %d is empty", i)
+		}
+	}
+	if got, want := strings.Join(strings.Fields(strings.Join(chunks, "")), ""), strings.Join(strings.Fields(text), ""); got != want {
+		t.Errorf("chunks lost or reordered text:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestEstimateTokensCountsRunes(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"日本語の文", 2},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSplitShortTextIsOneChunk(t *testing.T) {
+	text := "  A short text.  "
+	if chunks := Split(text, 100); len(chunks) != 1 || chunks[0] != text {
+		t.Errorf("Split = %q, want the text unchanged", chunks)
+	}
+}
+
+func TestSplitPacksParagraphs(t *testing.T) {
+	paragraph := strings.Repeat("word ", 7) + "end."
+	text := strings.Repeat(paragraph+"\n\n", 6)
+	chunks := Split(text, 20)
+	checkChunks(t, text, chunks, 20)
+	for i, chunk := range chunks {
+		if strings.Count(chunk, "end.") != strings.Count(chunk, "word word word word word word word end.") {
+			t.Errorf("chunk %d cuts a paragraph that fits: %q", i, chunk)
+		}
+	}
+}
+
+func TestSplitCountsSeparators(t *testing.T) {
+	// Two 16-character paragraphs estimate 4 tokens each, but joined by a blank
+	// line they take 34 characters, which no longer fits 8 tokens.
+	text := "aaaaaaaaaaaaaaaa\n\nbbbbbbbbbbbbbbbb\n\ncccc"
+	checkChunks(t, text, Split(text, 8), 8)
+	checkChunks(t, text, SplitAt(text, []int{18, 36}, 8), 8)
+}
+
+func TestSplitLongSentencesAndWords(t *testing.T) {
+	text := strings.Repeat("This sentence is fairly long. ", 20) + strings.Repeat("x", 300)
+	chunks := Split(text, 25)
+	checkChunks(t, text, chunks, 25)
+	if len(chunks) < 2 {
+		t.Errorf("Split returned %d chunks, want the paragraph split", len(chunks))
+	}
+}
+
+func TestSplitNeverCutsRunes(t *testing.T) {
+	for _, text := range []string{
+		strings.Repeat("日本語のテキスト", 100),
+		strings.Repeat("é", 301),
+		strings.Repeat("👍🏽", 150),
+	} {
+		checkChunks(t, text, Split(text, 10), 10)
+	}
+}
+
+func TestSplitAtKeepsChaptersTogether(t *testing.T) {
+	chapters := []string{
+		"# One\n\n" + strings.Repeat("First chapter text. ", 4),
+		"# Two\n\n" + strings.Repeat("Second chapter text. ", 4),
+		"# Three\n\n" + strings.Repeat("Third chapter text. ", 4),
+	}
+	text := strings.Join(chapters, "\n\n")
+	offsets := []int{0, len(chapters[0]) + 2, len(chapters[0]) + len(chapters[1]) + 4}
+
+	// Each chapter fits on its own but no two fit together.
+	chunks := SplitAt(text, offsets, 30)
+	checkChunks(t, text, chunks, 30)
+	if len(chunks) != 3 {
+		t.Fatalf("SplitAt returned %d chunks, want one per chapter: %q", len(chunks), chunks)
+	}
+	for i, chunk := range chunks {
+		if chunk != strings.TrimSpace(chapters[i]) {
+			t.Errorf("chunk %d = %q, want chapter %d whole", i, chunk, i+1)
+		}
+	}
+
+	// With room for two chapters the first two share a chunk.
+	chunks = SplitAt(text, offsets, 50)
+	checkChunks(t, text, chunks, 50)
+	if len(chunks) != 2 || !strings.HasPrefix(chunks[1], "# Three") {
+		t.Errorf("SplitAt = %q, want chapters one and two packed together", chunks)
+	}
+}
+
+func TestSplitAtSplitsOversizedChapter(t *testing.T) {
+	short := "# Short\n\nBrief."
+	long := "# Long\n\n" + strings.Repeat("A sentence in a long chapter. ", 30)
+	text := short + "\n\n" + long
+
+	chunks := SplitAt(text, []int{0, len(short) + 2}, 40)
+	checkChunks(t, text, chunks, 40)
+	if chunks[0] != short {
+		t.Errorf("first chunk = %q, want the short chapter alone", chunks[0])
+	}
+	if !strings.HasPrefix(chunks[1], "# Long") {
+		t.Errorf("second chunk = %q, want the long chapter to start a chunk", chunks[1])
+	}
+}
+
+func TestSplitAtIgnoresUnusableOffsets(t *testing.T) {
+	text := strings.Repeat("日本語の文章です。", 20) + "\n\n" + strings.Repeat("Another part. ", 10)
+	// 1 falls inside the first rune; the rest are out of range or out of order.
+	offsets := []int{1, -5, len(text) + 10, 60, 30}
+	checkChunks(t, text, SplitAt(text, offsets, 20), 20)
+}
+
+func TestParseLimits(t *testing.T) {
+	defaults := Config{MaxTokens: DefaultMaxTokens, Concurrency: DefaultConcurrency}
+	limits, err := ParseLimits(" gpt-4o-mini=8000:2 , llama3=4000,, claude=100000:6 ", defaults)
+	if err != nil {
+		t.Fatalf("ParseLimits returned error: %v", err)
+	}
+
+	tests := []struct {
+		model string
+		want  Config
+	}{
+		{"gpt-4o-mini", Config{MaxTokens: 8000, Concurrency: 2}},
+		{"llama3", Config{MaxTokens: 4000, Concurrency: DefaultConcurrency}},
+		{"claude", Config{MaxTokens: 100000, Concurrency: 6}},
+		{"unknown", defaults},
+	}
+	for _, tt := range tests {
+		if got := limits.For(tt.model); got != tt.want {
+			t.Errorf("For(%q) = %+v, want %+v", tt.model, got, tt.want)
+		}
+	}
+
+	if got := limits.Smallest([]string{"claude", "llama3", "gpt-4o-mini"}); got != (Config{MaxTokens: 4000, Concurrency: 2}) {
+		t.Errorf("Smallest = %+v, want the tightest tokens and concurrency", got)
+	}
+	if got := limits.Smallest(nil); got != defaults {
+		t.Errorf("Smallest(nil) = %+v, want the defaults", got)
+	}
+}
+
+func TestParseLimitsRejectsMalformedEntries(t *testing.T) {
+	for _, spec := range []string{
+		"gpt-4o",
+		"gpt-4o=",
+		"gpt-4o=many",
+		"gpt-4o=0",
+		"gpt-4o=-100",
+		"gpt-4o=8000:",
+		"gpt-4o=8000:0",
+		"gpt-4o=8000:two",
+		"gpt-4o=8000:2:3",
+	} {
+		if _, err := ParseLimits(spec, Config{MaxTokens: 1, Concurrency: 1}); err == nil {
+			t.Errorf("ParseLimits(%q) accepted a malformed entry", spec)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"sync"
+
+	"anpurnama/summarizer-backend/internal/service/extractor"
+)
+
+// flight is one pipeline run shared by every caller asking for the same summary.
+// It replays progress to callers that join late and is cancelled only once the
+// last caller has gone away.
+type flight struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+	refs   int
+
+	result *SummarizeResult
+	err    error
+
+	mu          sync.Mutex
+	stage       Stage
+	extracted   *extractor.ExtractedContent
+	deltas      []string
+	subscribers map[*subscriber]struct{}
+}
+
+// subscriber delivers a flight's progress to one caller from its own goroutine,
+// so a slow caller only ever holds up itself.
+type subscriber struct {
+	progress *Progress
+
+	mu      sync.Mutex
+	pending []func(*Progress)
+	closed  bool
+
+	wake chan struct{}
+	quit chan struct{}
+	done chan struct{}
+}
+
+func (p *Pipeline) Run(ctx context.Context, input SummarizeInput, progress Progress) (*SummarizeResult, error) {
+	key := flightKey(input)
+
+	p.flightsMu.Lock()
+	f, ok := p.flights[key]
+	if !ok {
+		flightCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{
+			done:        make(chan struct{}),
+			cancel:      cancel,
+			subscribers: make(map[*subscriber]struct{}),
+		}
+		p.flights[key] = f
+		go p.fly(flightCtx, key, f, input)
+	}
+	f.refs++
+	p.flightsMu.Unlock()
+	sub := f.subscribe(&progress)
+
+	select {
+	case <-f.done:
+		f.unsubscribe(sub)
+		sub.drain()
+		if f.err != nil {
+			return nil, f.err
+		}
+		return p.attribute(ctx, input, f.result)
+	case <-ctx.Done():
+		f.unsubscribe(sub)
+		sub.stop()
+		p.leave(key, f)
+		return nil, ctx.Err()
+	}
+}
+
+// fly streams whenever the summarizer can, so that streaming and non-streaming
+// callers share one flight and a streaming caller joining late still gets deltas.
+func (p *Pipeline) fly(ctx context.Context, key string, f *flight, input SummarizeInput) {
+	defer f.cancel()
+
+	progress := Progress{
+		OnStage:     f.publishStage,
+		OnExtracted: f.publishExtracted,
+	}
+	if p.SupportsStreaming() {
+		progress.OnDelta = f.publishDelta
+	}
+	f.result, f.err = p.run(ctx, input, progress)
+
+	p.flightsMu.Lock()
+	if p.flights[key] == f {
+		delete(p.flights, key)
+	}
+	p.flightsMu.Unlock()
+	close(f.done)
+}
+
+// attribute gives a caller that joined a flight started under another API key a
+// history row of its own. Like a cache hit it reuses the summary without counting
+// its cost again, which stays with the key that started the flight.
+func (p *Pipeline) attribute(ctx context.Context, input SummarizeInput, result *SummarizeResult) (*SummarizeResult, error) {
+	if result.Cached || stringValue(result.History.APIKey) == input.APIKey {
+		return result, nil
+	}
+
+	history := *result.History
+	history.ID = 0
+	history.APIKey = nonEmpty(input.APIKey)
+	history.PromptTokens, history.CompletionTokens, history.Cost = nil, nil, nil
+	if err := p.historyRepo.Create(ctx, &history); err != nil {
+		return nil, &StageError{Stage: StageSaving, Err: err}
+	}
+	p.index(ctx, history)
+	return &SummarizeResult{History: &history, Extracted: result.Extracted, Cached: true}, nil
+}
+
+func (p *Pipeline) leave(key string, f *flight) {
+	p.flightsMu.Lock()
+	defer p.flightsMu.Unlock()
+
+	f.refs--
+	if f.refs > 0 {
+		return
+	}
+	f.cancel()
+	if p.flights[key] == f {
+		delete(p.flights, key)
+	}
+}
+
+func (f *flight) subscribe(progress *Progress) *subscriber {
+	sub := &subscriber{
+		progress: progress,
+		wake:     make(chan struct{}, 1),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	f.mu.Lock()
+	if stage := f.stage; stage != "" {
+		sub.push(func(p *Progress) { p.stage(stage) })
+	}
+	if extracted := f.extracted; extracted != nil {
+		sub.push(func(p *Progress) { p.extracted(extracted) })
+	}
+	for _, delta := range f.deltas {
+		sub.push(func(p *Progress) { p.delta(delta) })
+	}
+	f.subscribers[sub] = struct{}{}
+	f.mu.Unlock()
+
+	go sub.run()
+	return sub
+}
+
+func (f *flight) unsubscribe(sub *subscriber) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.subscribers, sub)
+}
+
+func (f *flight) publish(event func(*Progress)) {
+	for sub := range f.subscribers {
+		sub.push(event)
+	}
+}
+
+func (f *flight) publishStage(stage Stage) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.stage = stage
+	f.publish(func(p *Progress) { p.stage(stage) })
+}
+
+func (f *flight) publishExtracted(content *extractor.ExtractedContent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.extracted = content
+	f.publish(func(p *Progress) { p.extracted(content) })
+}
+
+// publishDelta ignores subscriber errors: one caller going away must not abort
+// the summary the others are still waiting for.
+func (f *flight) publishDelta(delta string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.deltas = append(f.deltas, delta)
+	f.publish(func(p *Progress) { p.delta(delta) })
+	return nil
+}
+
+func (s *subscriber) push(event func(*Progress)) {
+	s.mu.Lock()
+	s.pending = append(s.pending, event)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *subscriber) run() {
+	defer close(s.done)
+	for {
+		s.mu.Lock()
+		events, closed := s.pending, s.closed
+		s.pending = nil
+		s.mu.Unlock()
+
+		for _, event := range events {
+			select {
+			case <-s.quit:
+				return
+			default:
+			}
+			event(s.progress)
+		}
+		if len(events) > 0 {
+			continue
+		}
+		if closed {
+			return
+		}
+		select {
+		case <-s.wake:
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// drain delivers everything queued so far and then stops the subscriber.
+func (s *subscriber) drain() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	<-s.done
+}
+
+// stop discards whatever is still queued. No callback runs after it returns.
+func (s *subscriber) stop() {
+	close(s.quit)
+	<-s.done
+}
+
+func flightKey(input SummarizeInput) string {
+	style := input.Style
+	if style == "" {
+		style = DefaultStyle
+	}
+
+	options, _ := json.Marshal(input.Options)
+	source := input.Source()
+	if input.Document == nil {
+		source = normalizeURL(source)
+	}
+	return strings.Join([]string{source, style, string(options)}, "\x00")
+}
+
+func normalizeURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	u.Host = host
+	if port != "" {
+		u.Host = host + ":" + port
+	}
+
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.RawQuery = u.Query().Encode()
+	u.Fragment = ""
+	return u.String()
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"anpurnama/summarizer-backend/internal/repository"
+	"anpurnama/summarizer-backend/internal/service/extractor"
+	"anpurnama/summarizer-backend/internal/service/llm"
+	"anpurnama/summarizer-backend/internal/service/prompt"
+)
+
+type memoryHistories struct {
+	repository.HistoryRepository
+	mu      sync.Mutex
+	created []repository.History
+}
+
+func (r *memoryHistories) FindCached(context.Context, repository.SummaryCacheKey) (*repository.History, error) {
+	return nil, nil
+}
+
+func (r *memoryHistories) Create(_ context.Context, history *repository.History) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	history.ID = len(r.created) + 1
+	r.created = append(r.created, *history)
+	return nil
+}
+
+type namedStyles struct {
+	repository.StyleRepository
+}
+
+func (namedStyles) GetByName(_ context.Context, name string) (*repository.Style, error) {
+	return &repository.Style{ID: 1, Name: name, PromptTemplate: "{{.Content}}"}, nil
+}
+
+type countingExtractor struct {
+	calls atomic.Int32
+}
+
+func (e *countingExtractor) Extract(context.Context, string) (*extractor.ExtractedContent, error) {
+	e.calls.Add(1)
+	return &extractor.ExtractedContent{Title: "Page", Content: "Page content."}, nil
+}
+
+func (e *countingExtractor) ExtractDocument(context.Context, extractor.Document) (*extractor.ExtractedContent, error) {
+	return nil, extractor.ErrUnsupportedDocument
+}
+
+// gatedSummarizer streams its first delta and then holds the summary back until
+// released, reporting when its context is cancelled instead.
+type gatedSummarizer struct {
+	calls     atomic.Int32
+	release   chan struct{}
+	cancelled chan struct{}
+}
+
+func newGatedSummarizer() *gatedSummarizer {
+	return &gatedSummarizer{release: make(chan struct{}), cancelled: make(chan struct{})}
+}
+
+func (s *gatedSummarizer) Summarize(ctx context.Context, styleName string, data prompt.Data) (*Summary, error) {
+	return s.SummarizeStream(ctx, styleName, data, func(string) error { return nil })
+}
+
+func (s *gatedSummarizer) SummarizeStream(ctx context.Context, _ string, _ prompt.Data, onDelta func(string) error) (*Summary, error) {
+	s.calls.Add(1)
+	onDelta("Sum")
+	select {
+	case <-s.release:
+	case <-ctx.Done():
+		close(s.cancelled)
+		return nil, ctx.Err()
+	}
+	onDelta("mary")
+
+	cost := 0.01
+	return &Summary{
+		Text:     "Summary",
+		Chunks:   1,
+		Provider: "fake",
+		Model:    "fake-model",
+		Usage:    llm.Usage{PromptTokens: 100, CompletionTokens: 10, Cost: &cost},
+	}, nil
+}
+
+func (s *gatedSummarizer) Translate(_ context.Context, text, _ string) (*Summary, error) {
+	return &Summary{Text: text}, nil
+}
+
+func (s *gatedSummarizer) PreferredModel() string {
+	return "fake-model"
+}
+
+type runResult struct {
+	result *SummarizeResult
+	err    error
+	deltas []string
+}
+
+// start runs input in the background, collecting its deltas when stream is set.
+func start(ctx context.Context, p *Pipeline, input SummarizeInput, stream bool) <-chan runResult {
+	done := make(chan runResult, 1)
+	go func() {
+		var r runResult
+		var progress Progress
+		if stream {
+			progress.OnDelta = func(delta string) error {
+				r.deltas = append(r.deltas, delta)
+				return nil
+			}
+		}
+		r.result, r.err = p.Run(ctx, input, progress)
+		done <- r
+	}()
+	return done
+}
+
+// waitForCallers waits until n callers have joined the flight for input.
+func waitForCallers(t *testing.T, p *Pipeline, input SummarizeInput, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		p.flightsMu.Lock()
+		f := p.flights[flightKey(input)]
+		joined := f != nil && f.refs == n
+		p.flightsMu.Unlock()
+		if joined {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%d callers never joined the flight", n)
+}
+
+func receive(t *testing.T, done <-chan runResult) runResult {
+	t.Helper()
+	select {
+	case r := <-done:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return")
+		return runResult{}
+	}
+}
+
+func newGatedPipeline() (*Pipeline, *memoryHistories, *countingExtractor, *gatedSummarizer) {
+	histories := &memoryHistories{}
+	extract := &countingExtractor{}
+	summarizer := newGatedSummarizer()
+	return NewPipeline(histories, namedStyles{}, extract, summarizer, nil), histories, extract, summarizer
+}
+
+func TestRunCoalescesIdenticalRequests(t *testing.T) {
+	p, histories, extract, summarizer := newGatedPipeline()
+	first := SummarizeInput{URL: "https://example.com/page", APIKey: "alice"}
+	sameKey := SummarizeInput{URL: "HTTPS://Example.com:443/page#intro", APIKey: "alice"}
+	otherKey := SummarizeInput{URL: "https://example.com/page", APIKey: "bob"}
+
+	ctx := context.Background()
+	a := start(ctx, p, first, true)
+	waitForCallers(t, p, first, 1)
+	b := start(ctx, p, sameKey, true)
+	c := start(ctx, p, otherKey, false)
+	waitForCallers(t, p, first, 3)
+	close(summarizer.release)
+
+	ra, rb, rc := receive(t, a), receive(t, b), receive(t, c)
+	for _, r := range []runResult{ra, rb, rc} {
+		if r.err != nil {
+			t.Fatalf("Run returned error: %v", r.err)
+		}
+	}
+	if extract.calls.Load() != 1 || summarizer.calls.Load() != 1 {
+		t.Errorf("extracted %d and summarized %d times, want once each", extract.calls.Load(), summarizer.calls.Load())
+	}
+	for _, r := range []runResult{ra, rb} {
+		if !slices.Equal(r.deltas, []string{"Sum", "mary"}) {
+			t.Errorf("streaming caller got deltas %q, want every delta including those sent before it joined", r.deltas)
+		}
+	}
+
+	if ra.result.History != rb.result.History || ra.result.Cached {
+		t.Errorf("callers with the same key should share the flight's row")
+	}
+	if stringValue(ra.result.History.APIKey) != "alice" || ra.result.History.Cost == nil {
+		t.Errorf("flight row = %+v, want it attributed to alice with its cost", ra.result.History)
+	}
+	other := rc.result.History
+	if stringValue(other.APIKey) != "bob" || other.Cost != nil || other.PromptTokens != nil || !rc.result.Cached {
+		t.Errorf("bob's row = %+v, want a reused summary with no cost of its own", other)
+	}
+	if other.ID == ra.result.History.ID || other.Summary != "Summary" {
+		t.Errorf("bob's row should be a separate row with the shared summary")
+	}
+	if len(histories.created) != 2 {
+		t.Errorf("created %d history rows, want one per API key", len(histories.created))
+	}
+}
+
+func TestRunLeaverDoesNotCancelOthers(t *testing.T) {
+	p, _, _, summarizer := newGatedPipeline()
+	input := SummarizeInput{URL: "https://example.com/page"}
+
+	leaving, leave := context.WithCancel(context.Background())
+	a := start(leaving, p, input, true)
+	waitForCallers(t, p, input, 1)
+	b := start(context.Background(), p, input, true)
+	waitForCallers(t, p, input, 2)
+
+	leave()
+	if r := receive(t, a); !errors.Is(r.err, context.Canceled) {
+		t.Fatalf("leaving caller err = %v, want context.Canceled", r.err)
+	}
+	waitForCallers(t, p, input, 1)
+
+	close(summarizer.release)
+	r := receive(t, b)
+	if r.err != nil || r.result.History.Summary != "Summary" {
+		t.Fatalf("remaining caller = %+v, %v, want the summary", r.result, r.err)
+	}
+	select {
+	case <-summarizer.cancelled:
+		t.Errorf("the flight was cancelled while a caller was still waiting")
+	default:
+	}
+}
+
+func TestRunLastLeaverCancelsFlight(t *testing.T) {
+	p, histories, _, summarizer := newGatedPipeline()
+	input := SummarizeInput{URL: "https://example.com/page"}
+
+	ctxA, cancelA := context.WithCancel(context.Background())
+	ctxB, cancelB := context.WithCancel(context.Background())
+	a := start(ctxA, p, input, false)
+	waitForCallers(t, p, input, 1)
+	b := start(ctxB, p, input, true)
+	waitForCallers(t, p, input, 2)
+
+	cancelA()
+	receive(t, a)
+	cancelB()
+	receive(t, b)
+
+	select {
+	case <-summarizer.cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the flight kept running after every caller left")
+	}
+
+	p.flightsMu.Lock()
+	remaining := len(p.flights)
+	p.flightsMu.Unlock()
+	if remaining != 0 {
+		t.Errorf("%d flights left behind", remaining)
+	}
+	if len(histories.created) != 0 {
+		t.Errorf("saved %d rows for an abandoned flight", len(histories.created))
+	}
+}
@@ -0,0 +1,46 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	ProviderOpenAI  = "openai"
+	ProviderHashing = "hashing"
+)
+
+type Embedder interface {
+	// Model identifies the vector space; vectors from different models are not comparable.
+	Model() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+type Config struct {
+	Provider   string
+	BaseURL    string
+	APIKey     string
+	Model      string
+	Dimensions int
+	Timeout    time.Duration
+}
+
+func New(config Config) (Embedder, error) {
+	switch config.Provider {
+	case ProviderHashing, "":
+		return NewHashing(config.Dimensions), nil
+	case ProviderOpenAI:
+		if config.BaseURL == "" {
+			config.BaseURL = "https://api.openai.com/v1"
+		}
+		if config.Model == "" {
+			config.Model = "text-embedding-3-small"
+		}
+		if config.Timeout <= 0 {
+			config.Timeout = 30 * time.Second
+		}
+		return NewOpenAI(config), nil
+	}
+	return nil, fmt.Errorf("unknown embedding provider %q", config.Provider)
+}
@@ -0,0 +1,71 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const DefaultHashingDimensions = 256
+
+// Hashing is a deterministic, offline embedder that hashes words and word bigrams
+// into a fixed number of buckets. It only captures lexical overlap, which is enough
+// for local development and tests.
+type Hashing struct {
+	dimensions int
+}
+
+func NewHashing(dimensions int) *Hashing {
+	if dimensions <= 0 {
+		dimensions = DefaultHashingDimensions
+	}
+	return &Hashing{dimensions: dimensions}
+}
+
+func (e *Hashing) Model() string {
+	return fmt.Sprintf("hashing-%d", e.dimensions)
+}
+
+func (e *Hashing) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+func (e *Hashing) embed(text string) []float32 {
+	vector := make([]float32, e.dimensions)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		e.add(vector, word, 1)
+		if i > 0 {
+			e.add(vector, words[i-1]+" "+word, 0.5)
+		}
+	}
+
+	// Dampen frequent features so long documents are not dominated by repetition.
+	for i, v := range vector {
+		if v != 0 {
+			vector[i] = float32(math.Copysign(math.Log1p(math.Abs(float64(v))), float64(v)))
+		}
+	}
+	return Normalize(vector)
+}
+
+func (e *Hashing) add(vector []float32, feature string, weight float32) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	vector[sum%uint64(e.dimensions)] += weight
+}
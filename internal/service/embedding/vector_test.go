@@ -0,0 +1,58 @@
+package embedding
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestNormalizeAndCosine(t *testing.T) {
+	v := Normalize([]float32{3, 4})
+	if math.Abs(float64(v[0])-0.6) > 1e-6 || math.Abs(float64(v[1])-0.8) > 1e-6 {
+		t.Errorf("Normalize(3, 4) = %v, want (0.6, 0.8)", v)
+	}
+	if got := Cosine(v, v); math.Abs(got-1) > 1e-6 {
+		t.Errorf("Cosine(v, v) = %v, want 1", got)
+	}
+	if got := Cosine(v, []float32{1}); got != 0 {
+		t.Errorf("Cosine of different lengths = %v, want 0", got)
+	}
+
+	zero := []float32{0, 0}
+	if got := Normalize(zero); got[0] != 0 || got[1] != 0 {
+		t.Errorf("Normalize(0, 0) = %v, want it unchanged", got)
+	}
+}
+
+func TestHashingIsDeterministicAndNormalized(t *testing.T) {
+	embedder := NewHashing(0)
+	if embedder.Model() != "hashing-256" {
+		t.Errorf("Model = %q, want the default dimensions", embedder.Model())
+	}
+
+	texts := []string{
+		"The quick brown fox jumps over the lazy dog.",
+		"the QUICK brown fox, jumps over the lazy dog",
+		"Quarterly revenue grew despite supply chain delays.",
+	}
+	first, _ := embedder.Embed(context.Background(), texts)
+	second, _ := embedder.Embed(context.Background(), texts)
+	for i := range texts {
+		if len(first[i]) != 256 {
+			t.Fatalf("vector %d has %d dimensions, want 256", i, len(first[i]))
+		}
+		if Cosine(first[i], second[i]) < 1-1e-6 {
+			t.Errorf("vector %d differs between calls", i)
+		}
+		if norm := Cosine(first[i], first[i]); math.Abs(norm-1) > 1e-5 {
+			t.Errorf("vector %d has squared norm %v, want 1", i, norm)
+		}
+	}
+
+	if same := Cosine(first[0], first[1]); same < 1-1e-6 {
+		t.Errorf("case and punctuation changed the vector: cosine %v", same)
+	}
+	if Cosine(first[0], first[2]) >= Cosine(first[0], first[1]) {
+		t.Errorf("unrelated text scored at least as high as the same text")
+	}
+}
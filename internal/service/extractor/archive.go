@@ -0,0 +1,119 @@
+package extractor
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	mediaTypeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	mediaTypeODT  = "application/vnd.oasis.opendocument.text"
+	mediaTypeEPUB = "application/epub+zip"
+
+	// maxArchiveEntryBytes and archiveExpansion guard against zip bombs: no
+	// single decompressed part of a document may exceed the former, and all
+	// parts read together may not exceed the latter times MaxBodyBytes.
+	maxArchiveEntryBytes = 64 << 20
+	archiveExpansion     = 4
+	maxMimetypeBytes     = 256
+)
+
+var zipMagic = []byte("PK\x03\x04")
+
+type archive struct {
+	reader *zip.Reader
+	// budget is how many more decompressed bytes may be read.
+	budget int64
+}
+
+func openArchive(data []byte, budget int64) (*archive, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open document archive: %w", err)
+	}
+	return &archive{reader: reader, budget: budget}, nil
+}
+
+// archiveType identifies a zip-based document by its contents, since servers
+// and browsers often label all of them application/zip or octet-stream.
+func archiveType(data []byte) string {
+	if !bytes.HasPrefix(data, zipMagic) {
+		return ""
+	}
+	a, err := openArchive(data, maxMimetypeBytes)
+	if err != nil {
+		return ""
+	}
+
+	if mimetype, err := a.read("mimetype"); err == nil {
+		switch strings.TrimSpace(string(mimetype)) {
+		case mediaTypeEPUB:
+			return mediaTypeEPUB
+		case mediaTypeODT:
+			return mediaTypeODT
+		}
+	}
+	if a.has("word/document.xml") {
+		return mediaTypeDOCX
+	}
+	return ""
+}
+
+func (a *archive) has(name string) bool {
+	for _, f := range a.reader.File {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *archive) read(name string) ([]byte, error) {
+	for _, f := range a.reader.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", name, err)
+		}
+		defer rc.Close()
+
+		limit := min(maxArchiveEntryBytes, a.budget)
+		data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if int64(len(data)) > limit {
+			return nil, fmt.Errorf("%w: %s expands beyond %d bytes", ErrTooLarge, name, limit)
+		}
+		a.budget -= int64(len(data))
+		return data, nil
+	}
+	return nil, fmt.Errorf("%s not found in document", name)
+}
+
+func (a *archive) decode(name string, v any) error {
+	data, err := a.read(name)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// attr looks an attribute up by local name, ignoring its namespace prefix.
+func attr(start xml.StartElement, name string) string {
+	for _, a := range start.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package extractor
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type archiveEntry struct {
+	name    string
+	content string
+}
+
+// buildArchive zips entries in order, storing a leading mimetype uncompressed
+// as EPUB and ODF require.
+func buildArchive(t *testing.T, entries []archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		method := zip.Deflate
+		if entry.name == "mimetype" {
+			method = zip.Store
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: entry.name, Method: method})
+		if err != nil {
+			t.Fatalf("create %s: %v", entry.name, err)
+		}
+		if _, err := w.Write([]byte(entry.content)); err != nil {
+			t.Fatalf("write %s: %v", entry.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close archive: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestArchiveBudgetSpansEntries(t *testing.T) {
+	data := buildArchive(t, []archiveEntry{
+		{"a.xml", strings.Repeat("a", 600)},
+		{"b.xml", strings.Repeat("b", 600)},
+	})
+
+	a, err := openArchive(data, 1000)
+	if err != nil {
+		t.Fatalf("openArchive returned error: %v", err)
+	}
+	if _, err := a.read("a.xml"); err != nil {
+		t.Fatalf("first entry within the budget failed: %v", err)
+	}
+	if _, err := a.read("b.xml"); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("err = %v, want ErrTooLarge once the entries together pass the budget", err)
+	}
+}
+
+func TestExtractDocumentRejectsZipBomb(t *testing.T) {
+	const maxBodyBytes = 16 << 10
+	// A megabyte of repeated markup compresses to a few kilobytes, well under
+	// MaxBodyBytes, but expands past archiveExpansion times it.
+	paragraph := `<w:p><w:r><w:t>` + strings.Repeat("z", 1000) + `</w:t></w:r></w:p>`
+	bomb := buildArchive(t, []archiveEntry{
+		{"word/document.xml", `<w:document xmlns:w="w"><w:body>` + strings.Repeat(paragraph, 1000) + `</w:body></w:document>`},
+	})
+	if len(bomb) > maxBodyBytes {
+		t.Fatalf("fixture is %d bytes, want it under the body limit", len(bomb))
+	}
+
+	ce := newLocalExtractor(t, maxBodyBytes)
+	_, err := ce.ExtractDocument(context.Background(), Document{Name: "bomb.docx", Data: bomb})
+	if !errors.Is(err, ErrTooLarge) {
+		t.Errorf("err = %v, want ErrTooLarge", err)
+	}
+
+	small := buildArchive(t, []archiveEntry{
+		{"word/document.xml", `<w:document xmlns:w="w"><w:body>` + strings.Repeat(paragraph, 10) + `</w:body></w:document>`},
+	})
+	if _, err := ce.ExtractDocument(context.Background(), Document{Name: "small.docx", Data: small}); err != nil {
+		t.Errorf("a document within archiveExpansion failed: %v", err)
+	}
+}
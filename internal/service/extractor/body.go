@@ -0,0 +1,84 @@
+package extractor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"golang.org/x/net/html/charset"
+)
+
+const DefaultMaxBodyBytes = 32 << 20
+
+var (
+	ErrTooLarge               = errors.New("response body is too large")
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+)
+
+// acceptsContentType rejects a response by its declared type before the body is
+// read. Generic types are let through to be sniffed.
+func acceptsContentType(mediaType string) bool {
+	switch mediaType {
+	case "", "application/octet-stream", "binary/octet-stream", "application/zip",
+		"text/html", "application/xhtml+xml", "text/plain", "text/markdown",
+		"application/pdf", mediaTypeDOCX, mediaTypeODT, mediaTypeEPUB:
+		return true
+	}
+	return false
+}
+
+// readBody stops reading as soon as the body passes limit, so a huge or endless
+// response never sits in memory.
+func readBody(resp *http.Response, limit int64) ([]byte, error) {
+	if resp.ContentLength > limit {
+		return nil, fmt.Errorf("%w: %d bytes exceeds the %d byte limit", ErrTooLarge, resp.ContentLength, limit)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: body exceeds the %d byte limit", ErrTooLarge, limit)
+	}
+	return body, nil
+}
+
+// responseType picks the extractor for a fetched page. Documents are recognized
+// by their content type or magic bytes, and generic or missing types by sniffing.
+func responseType(contentType string, body []byte) (string, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if isPDF(mediaType, body) {
+		return "application/pdf", nil
+	}
+	if archive := archiveType(body); archive != "" {
+		return archive, nil
+	}
+	switch mediaType {
+	case mediaTypeDOCX, mediaTypeODT, mediaTypeEPUB, "text/html", "application/xhtml+xml", "text/plain", "text/markdown":
+		return mediaType, nil
+	}
+
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(body))
+	switch sniffed {
+	case "text/html", "text/plain":
+		return sniffed, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedContentType, sniffed)
+}
+
+// toUTF8 decodes text using the charset from the Content-Type header, a byte
+// order mark or an HTML meta tag, falling back to sniffing.
+func toUTF8(body []byte, contentType string) ([]byte, error) {
+	encoding, name, _ := charset.DetermineEncoding(body, contentType)
+	if name == "utf-8" {
+		return body, nil
+	}
+	decoded, err := encoding.NewDecoder().Bytes(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s text: %w", name, err)
+	}
+	return decoded, nil
+}
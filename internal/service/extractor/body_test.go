@@ -0,0 +1,159 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newPageServer serves body with the given Content-Type, and with no
+// Content-Length when chunked is set.
+func newPageServer(t *testing.T, contentType string, body []byte, chunked bool) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		if !chunked {
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		}
+		for rest := body; len(rest) > 0; {
+			n := min(512, len(rest))
+			w.Write(rest[:n])
+			rest = rest[n:]
+			if chunked {
+				w.(http.Flusher).Flush()
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newLocalExtractor(t *testing.T, maxBodyBytes int64) ContentExtractor {
+	t.Helper()
+	ce, err := NewContentExtractor(Config{AllowedHosts: []string{"127.0.0.1"}, MaxBodyBytes: maxBodyBytes, Languages: []string{"en", "fr", "ja"}}, nil)
+	if err != nil {
+		t.Fatalf("NewContentExtractor returned error: %v", err)
+	}
+	return ce
+}
+
+const articleHTML = `<html><head><title>Charset test</title></head><body><article>
+<h1>Charset test</h1><p>%s</p><p>A second paragraph so that readability keeps the article body intact.</p>
+</article></body></html>`
+
+func TestExtractRejectsOversizedBody(t *testing.T) {
+	body := []byte(strings.Repeat("<p>padding</p>", 200))
+	ce := newLocalExtractor(t, 1024)
+
+	for _, chunked := range []bool{false, true} {
+		server := newPageServer(t, "text/html", body, chunked)
+		if _, err := ce.Extract(context.Background(), server.URL); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("chunked=%v: err = %v, want ErrTooLarge", chunked, err)
+		}
+	}
+
+	server := newPageServer(t, "text/html", body[:1024], false)
+	if _, err := ce.Extract(context.Background(), server.URL); errors.Is(err, ErrTooLarge) {
+		t.Errorf("a body of exactly the limit was rejected: %v", err)
+	}
+}
+
+func TestExtractDoesNotTrustContentType(t *testing.T) {
+	ce := newLocalExtractor(t, DefaultMaxBodyBytes)
+	page := []byte(strings.Replace(articleHTML, "%s", "Mislabelled pages are still read by what their bytes contain.", 1))
+	docx := buildArchive(t, []archiveEntry{
+		{"word/document.xml", `<w:document xmlns:w="w"><w:body><w:p><w:r><w:t>Served as plain text.</w:t></w:r></w:p></w:body></w:document>`},
+	})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		want        string
+		err         error
+	}{
+		{"html as octet-stream", "application/octet-stream", page, "Mislabelled pages", nil},
+		{"html without a type", "", page, "Mislabelled pages", nil},
+		{"docx as text/plain", "text/plain", docx, "Served as plain text.", nil},
+		{"docx as zip", "application/zip", docx, "Served as plain text.", nil},
+		{"image type", "image/png", page, "", ErrUnsupportedContentType},
+		{"binary as octet-stream", "application/octet-stream", []byte("\x00\x01\x02\x89PNG\r\n\x1a\n"), "", ErrUnsupportedContentType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newPageServer(t, tt.contentType, tt.body, false)
+			result, err := ce.Extract(context.Background(), server.URL)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Extract returned error: %v", err)
+			}
+			if !strings.Contains(result.Content, tt.want) {
+				t.Errorf("content = %q, want it to contain %q", result.Content, tt.want)
+			}
+		})
+	}
+}
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        []byte
+		contentType string
+		want        string
+	}{
+		{"utf-8", []byte("café"), "text/plain; charset=utf-8", "café"},
+		{"latin-1 header", []byte("caf\xe9"), "text/plain; charset=iso-8859-1", "café"},
+		{"windows-1252 header", []byte("\x93quoted\x94"), "text/html; charset=windows-1252", "“quoted”"},
+		{"meta tag", []byte(`<html><head><meta charset="shift_jis"></head><body>` + "\x93\xfa\x96\x7b" + `</body></html>`), "text/html", "日本"},
+		{"utf-16 bom", []byte("\xff\xfeh\x00i\x00"), "text/plain", "hi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toUTF8(tt.body, tt.contentType)
+			if err != nil {
+				t.Fatalf("toUTF8 returned error: %v", err)
+			}
+			if !strings.Contains(string(got), tt.want) {
+				t.Errorf("toUTF8 = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractTranscodesDeclaredCharset(t *testing.T) {
+	ce := newLocalExtractor(t, DefaultMaxBodyBytes)
+	text := "Le café était très animé pendant toute la soirée, et les habitués parlaient de la météo."
+	latin1 := encodeLatin1(t, strings.Replace(articleHTML, "%s", text, 1))
+
+	server := newPageServer(t, "text/html; charset=iso-8859-1", latin1, false)
+	result, err := ce.Extract(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	if !strings.Contains(result.Content, "café était très animé") {
+		t.Errorf("content = %q, want the Latin-1 text decoded", result.Content)
+	}
+}
+
+func encodeLatin1(t *testing.T, s string) []byte {
+	t.Helper()
+	var b []byte
+	for _, r := range s {
+		if r > 0xff {
+			t.Fatalf("%q is not representable in Latin-1", r)
+		}
+		b = append(b, byte(r))
+	}
+	return b
+}
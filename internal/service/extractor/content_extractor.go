@@ -5,57 +5,131 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"log"
+	"mime"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
+	"anpurnama/summarizer-backend/internal/repository"
+
 	"github.com/go-shiori/go-readability"
-	"github.com/pemistahl/lingua-go"
 )
 
 type ContentExtractor interface {
 	Extract(ctx context.Context, url string) (*ExtractedContent, error)
+	ExtractDocument(ctx context.Context, doc Document) (*ExtractedContent, error)
 }
 
+// ExtractedContent holds the plain text of a source in Content, used for search
+// and language detection, and when the source is HTML a Markdown rendering that
+// keeps its structure for the model.
 type ExtractedContent struct {
-	Title       string
-	Content     string
-	Language    string
-	SiteName    string
-	Author      string
-	Excerpt     string
-	ImageURL    string
-	PublishDate string
+	Title    string
+	Content  string
+	Markdown string
+	Language string
+	// LanguageConfidence is the detector's confidence in Language, from 0 to 1.
+	LanguageConfidence float64
+	SiteName           string
+	Author             string
+	Excerpt            string
+	ImageURL           string
+	PublishDate        string
+	Chapters           []Chapter
+}
+
+type Config struct {
+	// Languages lists the ISO 639-1 codes to detect, or just AllLanguages.
+	Languages []string
+	// AllowedHosts lists hostnames, IPs and CIDRs that may be fetched even when
+	// they resolve to private or loopback addresses.
+	AllowedHosts []string
+	// DeniedHosts lists hostnames, IPs and CIDRs that are never fetched; it wins
+	// over AllowedHosts.
+	DeniedHosts []string
+	// MaxBodyBytes caps fetched responses and uploaded documents; zero means
+	// DefaultMaxBodyBytes.
+	MaxBodyBytes int64
+	// UserAgent is sent with every fetch and, when RespectRobots is set, picks
+	// the robots.txt group by its product token.
+	UserAgent     string
+	RespectRobots bool
+	// RobotsTTL is how long a host's robots.txt is cached; zero means DefaultRobotsTTL.
+	RobotsTTL time.Duration
+	// HostConcurrency bounds simultaneous fetches per host; zero means
+	// DefaultHostConcurrency.
+	HostConcurrency int
+	// HostDelay is the minimum spacing between fetches to one host. A longer
+	// robots.txt Crawl-delay takes precedence.
+	HostDelay time.Duration
+	// MaxCrawlDelay caps the robots.txt Crawl-delay that is honoured; zero means
+	// DefaultMaxCrawlDelay.
+	MaxCrawlDelay time.Duration
 }
 
+const (
+	DefaultUserAgent     = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/121.0.0.0 Safari/537.36"
+	DefaultRobotsTTL     = time.Hour
+	DefaultMaxCrawlDelay = 30 * time.Second
+	maxRedirects         = 10
+)
+
 type contentExtractor struct {
-	languageDetector lingua.LanguageDetector
+	languageDetector *LanguageDetector
 	httpClient       *http.Client
+	maxBodyBytes     int64
+	userAgent        string
+	robots           *robotsCache
+	hosts            *hostLimiter
+	rules            repository.DomainRuleRepository
 }
 
-func NewContentExtractor() (ContentExtractor, error) {
-	languages := []lingua.Language{
-		lingua.English,
-		lingua.Indonesian,
-		lingua.Spanish,
-		lingua.French,
-		lingua.German,
+// NewContentExtractor applies the per-domain extraction rules from rules, which
+// may be nil.
+func NewContentExtractor(config Config, rules repository.DomainRuleRepository) (ContentExtractor, error) {
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if len(config.Languages) == 0 {
+		config.Languages = DefaultLanguages
+	}
+	if config.UserAgent == "" {
+		config.UserAgent = DefaultUserAgent
+	}
+	if config.RobotsTTL <= 0 {
+		config.RobotsTTL = DefaultRobotsTTL
+	}
+	if config.MaxCrawlDelay <= 0 {
+		config.MaxCrawlDelay = DefaultMaxCrawlDelay
+	}
+	detector, err := NewLanguageDetector(config.Languages)
+	if err != nil {
+		return nil, err
 	}
 
-	detector := lingua.NewLanguageDetectorBuilder().
-		FromLanguages(languages...).
-		Build()
-
-	client := &http.Client{
-		Timeout: 30 * time.Second,
+	client, err := newFetchClient(config)
+	if err != nil {
+		return nil, err
 	}
 
-	return &contentExtractor{
+	ce := &contentExtractor{
 		languageDetector: detector,
 		httpClient:       client,
-	}, nil
+		maxBodyBytes:     config.MaxBodyBytes,
+		userAgent:        config.UserAgent,
+		hosts:            newHostLimiter(config.HostConcurrency, config.HostDelay),
+		rules:            rules,
+	}
+	if config.RespectRobots {
+		robotsClient := *client
+		ce.robots = newRobotsCache(&robotsClient, config.UserAgent, config.RobotsTTL, config.MaxCrawlDelay)
+	}
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return ce, nil
 }
 
 func (ce *contentExtractor) Extract(ctx context.Context, source string) (*ExtractedContent, error) {
@@ -69,44 +143,161 @@ func (ce *contentExtractor) Extract(ctx context.Context, source string) (*Extrac
 		return nil, fmt.Errorf("invalid URL format: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Add headers to mimic a regular browser
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/121.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
-
 	start := time.Now()
-	resp, err := ce.httpClient.Do(req)
+	resp, rule, release, err := ce.get(ctx, urlRes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch webpage: %w", err)
+		return nil, err
 	}
+	defer release()
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("webpage returned status code: %d", resp.StatusCode)
 	}
 
-	// Read the body once and create a bytes.Reader for readability
-	body, err := io.ReadAll(resp.Body)
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); !acceptsContentType(mediaType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, mediaType)
+	}
+
+	body, err := readBody(resp, ce.maxBodyBytes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
-	// Create a new reader from the body bytes
-	article, err := readability.FromReader(bytes.NewReader(body), urlRes)
+	mediaType, err := responseType(contentType, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse webpage content: %w", err)
+		return nil, err
+	}
+	switch mediaType {
+	case "text/html", "application/xhtml+xml", "text/plain", "text/markdown":
+		if body, err = toUTF8(body, contentType); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := ce.parse(mediaType, body, resp.Request.URL, rule)
+	if err != nil {
+		return nil, err
+	}
+	if result.Title == "" {
+		result.Title = derivedTitle("", result.Content)
 	}
 	log.Printf("Scraping process completed in %s", time.Since(start))
 
-	// Detect language
-	langStart := time.Now()
-	language := ce.detectLanguage(article.TextContent)
-	log.Printf("Language detection completed in %s", time.Since(langStart))
+	ce.detect(result)
+	return result, nil
+}
+
+// get fetches target and follows redirects itself, so every hop goes through
+// robots.txt and the per-host limiter with the User-Agent of its own domain
+// rule. It returns the rule for the final URL. The caller closes the body and
+// then calls release.
+func (ce *contentExtractor) get(ctx context.Context, target *url.URL) (*http.Response, *repository.DomainRule, func(), error) {
+	for redirects := 0; ; redirects++ {
+		resp, rule, release, err := ce.getOnce(ctx, target)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		location, err := resp.Location()
+		if !isRedirect(resp.StatusCode) || err != nil {
+			return resp, rule, release, nil
+		}
+		resp.Body.Close()
+		release()
+
+		if redirects == maxRedirects {
+			return nil, nil, nil, fmt.Errorf("failed to fetch webpage: stopped after %d redirects", maxRedirects)
+		}
+		target = location
+	}
+}
+
+func (ce *contentExtractor) getOnce(ctx context.Context, target *url.URL) (*http.Response, *repository.DomainRule, func(), error) {
+	var rule *repository.DomainRule
+	if ce.rules != nil {
+		var err error
+		if rule, err = ce.rules.FindForHost(ctx, target.Hostname()); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to load domain rule: %w", err)
+		}
+	}
+	userAgent := ce.userAgent
+	if rule != nil && rule.UserAgent != nil {
+		userAgent = *rule.UserAgent
+	}
+
+	var crawlDelay time.Duration
+	if ce.robots != nil {
+		var err error
+		if crawlDelay, err = ce.robots.check(ctx, target, userAgent); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	release, err := ce.hosts.wait(ctx, target.Hostname(), crawlDelay)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		release()
+		return nil, nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,application/pdf;q=0.9,application/epub+zip;q=0.8,image/webp,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
+
+	resp, err := ce.httpClient.Do(req)
+	if err != nil {
+		release()
+		return nil, nil, nil, fmt.Errorf("failed to fetch webpage: %w", err)
+	}
+	return resp, rule, release, nil
+}
+
+func isRedirect(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// ModelText is the text to summarize: the Markdown rendering when there is one.
+func (c *ExtractedContent) ModelText() string {
+	if c.Markdown != "" {
+		return c.Markdown
+	}
+	return c.Content
+}
+
+// ChapterOffsets are the offsets in ModelText where chapters start.
+func (c *ExtractedContent) ChapterOffsets() []int {
+	if c.Markdown != "" {
+		return nil
+	}
+	offsets := make([]int, len(c.Chapters))
+	for i, chapter := range c.Chapters {
+		offsets[i] = chapter.Offset
+	}
+	return offsets
+}
+
+func (ce *contentExtractor) fromHTML(body []byte, pageURL *url.URL, rule *repository.DomainRule) (*ExtractedContent, error) {
+	var overrides ruleOverrides
+	if rule != nil {
+		var err error
+		if body, overrides, err = applyRule(body, rule); err != nil {
+			return nil, err
+		}
+	}
+
+	article, err := readability.FromReader(bytes.NewReader(body), pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse webpage content: %w", err)
+	}
 
 	// Handle nil PublishedTime
 	publishDate := ""
@@ -114,25 +305,45 @@ func (ce *contentExtractor) Extract(ctx context.Context, source string) (*Extrac
 		publishDate = article.PublishedTime.Format(time.RFC3339)
 	}
 
-	result := &ExtractedContent{
+	markdown, err := htmlToMarkdown(article.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &ExtractedContent{
 		Title:       article.Title,
 		Content:     article.TextContent,
-		Language:    language,
+		Markdown:    markdown,
 		SiteName:    article.SiteName,
 		Author:      article.Byline,
 		Excerpt:     article.Excerpt,
-		ImageURL:    article.Image,
+		ImageURL:    resolveURL(pageURL, article.Image),
 		PublishDate: publishDate,
 	}
-
-	return result, nil
+	if overrides.title != "" {
+		content.Title = overrides.title
+	}
+	if overrides.author != "" {
+		content.Author = overrides.author
+	}
+	return content, nil
 }
 
-func (ce *contentExtractor) detectLanguage(text string) string {
-	language, ableToDetect := ce.languageDetector.DetectLanguageOf(text)
-	if !ableToDetect {
+// resolveURL makes a page's relative or protocol-relative link absolute, or
+// returns "" when it cannot be resolved to an http(s) URL.
+func resolveURL(pageURL *url.URL, ref string) string {
+	if ref == "" || pageURL == nil {
+		return ""
+	}
+	resolved, err := pageURL.Parse(strings.TrimSpace(ref))
+	if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") || resolved.Host == "" {
 		return ""
-	} else {
-		return language.String()
 	}
+	return resolved.String()
+}
+
+func (ce *contentExtractor) detect(content *ExtractedContent) {
+	langStart := time.Now()
+	content.Language, content.LanguageConfidence = ce.languageDetector.Detect(content.Content)
+	log.Printf("Language detection completed in %s", time.Since(langStart))
 }
@@ -0,0 +1,197 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+var ErrBlockedDestination = errors.New("destination is not allowed")
+
+// reservedPrefixes are non-public ranges the netip predicates do not cover:
+// "this network", carrier-grade NAT, IETF protocol assignments, benchmarking,
+// the reserved class E block including broadcast, and local-use NAT64.
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// Addresses under the well-known NAT64 prefix carry an IPv4 address in their
+// last 32 bits and 6to4 addresses carry one in bits 16 to 48; both are judged
+// by that IPv4 address.
+var (
+	nat64Prefix     = netip.MustParsePrefix("64:ff9b::/96")
+	sixToFourPrefix = netip.MustParsePrefix("2002::/16")
+)
+
+// hostRules matches hostnames, including their subdomains, and IP ranges.
+type hostRules struct {
+	names    []string
+	prefixes []netip.Prefix
+}
+
+// parseHostRules reads hostnames, IP addresses and CIDRs.
+func parseHostRules(entries []string) (hostRules, error) {
+	var rules hostRules
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return hostRules{}, fmt.Errorf("invalid CIDR %q: %w", entry, err)
+			}
+			rules.prefixes = append(rules.prefixes, prefix.Masked())
+			continue
+		}
+		if addr, err := netip.ParseAddr(strings.Trim(entry, "[]")); err == nil {
+			addr = addr.Unmap()
+			rules.prefixes = append(rules.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		rules.names = append(rules.names, strings.Trim(entry, "."))
+	}
+	return rules, nil
+}
+
+func (r hostRules) matchesName(host string) bool {
+	for _, name := range r.names {
+		if host == name || strings.HasSuffix(host, "."+name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r hostRules) matchesAddr(addr netip.Addr) bool {
+	for _, prefix := range r.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// safeDialer resolves hosts itself and connects to the address it checked, so a
+// second DNS answer cannot swap in an internal address. Every connection goes
+// through it, including those made for redirects.
+type safeDialer struct {
+	allow    hostRules
+	deny     hostRules
+	dialer   *net.Dialer
+	resolver *net.Resolver
+}
+
+func (d *safeDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if d.deny.matchesName(host) {
+		return nil, fmt.Errorf("%w: %s", ErrBlockedDestination, host)
+	}
+	trusted := d.allow.matchesName(host)
+
+	ipNetwork := "ip"
+	switch network {
+	case "tcp4":
+		ipNetwork = "ip4"
+	case "tcp6":
+		ipNetwork = "ip6"
+	}
+	addrs, err := d.resolver.LookupNetIP(ctx, ipNetwork, host)
+	if err != nil {
+		return nil, err
+	}
+	for i, addr := range addrs {
+		addrs[i] = addr.Unmap()
+		if d.permits(addrs[i], trusted) {
+			continue
+		}
+		if addrs[i].String() == host {
+			return nil, fmt.Errorf("%w: %s", ErrBlockedDestination, host)
+		}
+		return nil, fmt.Errorf("%w: %s resolves to %s", ErrBlockedDestination, host, addrs[i])
+	}
+
+	var lastErr error
+	for _, addr := range addrs {
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(addr.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// permits rejects denied addresses and, unless the host or address is
+// allowlisted, anything that is not a public unicast address.
+func (d *safeDialer) permits(addr netip.Addr, trusted bool) bool {
+	if d.deny.matchesAddr(addr) {
+		return false
+	}
+	if embedded, ok := embeddedIPv4(addr); ok {
+		return d.permits(embedded, trusted || d.allow.matchesAddr(addr))
+	}
+	if trusted || d.allow.matchesAddr(addr) {
+		return true
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return !(addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified())
+}
+
+func embeddedIPv4(addr netip.Addr) (netip.Addr, bool) {
+	b := addr.As16()
+	switch {
+	case nat64Prefix.Contains(addr):
+		return netip.AddrFrom4([4]byte(b[12:16])), true
+	case sixToFourPrefix.Contains(addr):
+		return netip.AddrFrom4([4]byte(b[2:6])), true
+	}
+	return netip.Addr{}, false
+}
+
+func newFetchClient(config Config) (*http.Client, error) {
+	allow, err := parseHostRules(config.AllowedHosts)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := parseHostRules(config.DeniedHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &safeDialer{
+		allow:    allow,
+		deny:     deny,
+		dialer:   &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second},
+		resolver: net.DefaultResolver,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would connect on our behalf and bypass the dialer's checks.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: transport,
+	}, nil
+}
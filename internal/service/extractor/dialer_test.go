@@ -0,0 +1,146 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+)
+
+func newTestDialer(t *testing.T, allowed, denied []string) *safeDialer {
+	t.Helper()
+	allow, err := parseHostRules(allowed)
+	if err != nil {
+		t.Fatalf("parseHostRules(%q) returned error: %v", allowed, err)
+	}
+	deny, err := parseHostRules(denied)
+	if err != nil {
+		t.Fatalf("parseHostRules(%q) returned error: %v", denied, err)
+	}
+	return &safeDialer{allow: allow, deny: deny}
+}
+
+func TestDialerPermits(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"192.0.0.8", false},
+		{"198.18.0.1", false},
+		{"240.0.0.1", false},
+		{"255.255.255.255", false},
+		{"224.0.0.1", false},
+		{"::", false},
+		{"64:ff9b::7f00:1", false},
+		{"64:ff9b::a00:1", false},
+		{"64:ff9b::5db8:d822", true},
+		{"64:ff9b:1::1", false},
+		{"2002:7f00:1::1", false},
+		{"2002:a9fe:a9fe::1", false},
+		{"2002:5db8:d822::1", true},
+	}
+
+	d := newTestDialer(t, nil, nil)
+	for _, tt := range tests {
+		addr := netip.MustParseAddr(tt.addr).Unmap()
+		if got := d.permits(addr, false); got != tt.want {
+			t.Errorf("permits(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+
+	// The dialer unmaps resolved addresses before checking them.
+	if d.permits(netip.MustParseAddr("::ffff:127.0.0.1").Unmap(), false) {
+		t.Errorf("IPv4-mapped loopback was permitted")
+	}
+}
+
+func TestDialerAllowAndDenyLists(t *testing.T) {
+	d := newTestDialer(t,
+		[]string{"10.0.0.0/8", "127.0.0.1", "internal.example"},
+		[]string{"10.9.0.0/16", "203.0.113.0/24", "64:ff9b::a0a:a0a/128"},
+	)
+
+	tests := []struct {
+		addr    string
+		trusted bool
+		want    bool
+	}{
+		{"10.1.2.3", false, true},
+		{"127.0.0.1", false, true},
+		{"64:ff9b::7f00:1", false, true},
+		{"127.0.0.2", false, false},
+		{"192.168.1.1", true, true},
+		{"10.9.1.1", false, false},
+		{"10.9.1.1", true, false},
+		{"203.0.113.5", false, false},
+		{"64:ff9b::cb00:7105", false, false},
+		{"64:ff9b::a0a:a0a", false, false},
+	}
+	for _, tt := range tests {
+		if got := d.permits(netip.MustParseAddr(tt.addr), tt.trusted); got != tt.want {
+			t.Errorf("permits(%s, trusted=%v) = %v, want %v", tt.addr, tt.trusted, got, tt.want)
+		}
+	}
+
+	if !d.allow.matchesName("api.internal.example") || d.allow.matchesName("notinternal.example") {
+		t.Errorf("allowlisted names should match themselves and their subdomains only")
+	}
+}
+
+func TestParseHostRulesRejectsInvalidCIDR(t *testing.T) {
+	if _, err := parseHostRules([]string{"10.0.0.0/33"}); err == nil {
+		t.Errorf("parseHostRules accepted an invalid CIDR")
+	}
+}
+
+func TestFetchRefusesRedirectToPrivateAddress(t *testing.T) {
+	var started, secretFetched atomic.Bool
+	var port string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/secret" {
+			secretFetched.Store(true)
+			w.Write([]byte("<html><body><p>internal</p></body></html>"))
+			return
+		}
+		started.Store(true)
+		http.Redirect(w, r, "http://127.0.0.1:"+port+"/secret", http.StatusFound)
+	}))
+	defer server.Close()
+	_, port, _ = net.SplitHostPort(server.Listener.Addr().String())
+
+	// Only the name is allowlisted, so the redirect to the bare address is
+	// judged on its own and refused.
+	ce, err := NewContentExtractor(Config{AllowedHosts: []string{"localhost"}}, nil)
+	if err != nil {
+		t.Fatalf("NewContentExtractor returned error: %v", err)
+	}
+	_, err = ce.Extract(context.Background(), "http://localhost:"+port+"/start")
+	if !errors.Is(err, ErrBlockedDestination) {
+		t.Fatalf("err = %v, want ErrBlockedDestination", err)
+	}
+	if !started.Load() || secretFetched.Load() {
+		t.Errorf("want the allowlisted name fetched and its private redirect target refused")
+	}
+
+	_, err = ce.Extract(context.Background(), server.URL+"/start")
+	if !errors.Is(err, ErrBlockedDestination) {
+		t.Errorf("err = %v, want a direct loopback fetch refused too", err)
+	}
+}
@@ -0,0 +1,120 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"anpurnama/summarizer-backend/internal/repository"
+)
+
+const maxDerivedTitleRunes = 80
+
+var (
+	ErrEmptyDocument       = errors.New("document is empty")
+	ErrUnsupportedDocument = errors.New("unsupported document type")
+)
+
+// Document is content supplied directly by the client instead of fetched from a URL.
+type Document struct {
+	Name        string
+	ContentType string
+	Title       string
+	Data        []byte
+}
+
+func (ce *contentExtractor) ExtractDocument(ctx context.Context, doc Document) (*ExtractedContent, error) {
+	if len(strings.TrimSpace(string(doc.Data))) == 0 {
+		return nil, ErrEmptyDocument
+	}
+	if int64(len(doc.Data)) > ce.maxBodyBytes {
+		return nil, fmt.Errorf("%w: %d bytes exceeds the %d byte limit", ErrTooLarge, len(doc.Data), ce.maxBodyBytes)
+	}
+
+	result, err := ce.parse(documentType(doc), doc.Data, &url.URL{Scheme: "file", Path: "/" + doc.Name}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.Title != "" {
+		result.Title = doc.Title
+	}
+	if result.Title == "" {
+		result.Title = derivedTitle(doc.Name, result.Content)
+	}
+
+	ce.detect(result)
+	return result, nil
+}
+
+func (ce *contentExtractor) parse(mediaType string, data []byte, base *url.URL, rule *repository.DomainRule) (*ExtractedContent, error) {
+	switch mediaType {
+	case "text/plain", "text/markdown":
+		if !utf8.Valid(data) {
+			return nil, fmt.Errorf("%w: text is not valid UTF-8", ErrUnsupportedDocument)
+		}
+		return &ExtractedContent{Content: strings.TrimSpace(string(data))}, nil
+	case "text/html", "application/xhtml+xml":
+		return ce.fromHTML(data, base, rule)
+	case "application/pdf":
+		return fromPDF(data)
+	case mediaTypeDOCX:
+		return fromDOCX(data, ce.maxBodyBytes*archiveExpansion)
+	case mediaTypeODT:
+		return fromODT(data, ce.maxBodyBytes*archiveExpansion)
+	case mediaTypeEPUB:
+		return fromEPUB(data, ce.maxBodyBytes*archiveExpansion)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedDocument, mediaType)
+}
+
+// documentType trusts a declared content type unless it is generic, in which case
+// the file extension and then the bytes themselves decide.
+func documentType(doc Document) string {
+	mediaType, _, _ := mime.ParseMediaType(doc.ContentType)
+	if mediaType != "" && mediaType != "application/octet-stream" && mediaType != "application/zip" {
+		return mediaType
+	}
+
+	switch strings.ToLower(path.Ext(doc.Name)) {
+	case ".txt", ".text":
+		return "text/plain"
+	case ".md", ".markdown":
+		return "text/markdown"
+	case ".html", ".htm":
+		return "text/html"
+	case ".pdf":
+		return "application/pdf"
+	case ".docx":
+		return mediaTypeDOCX
+	case ".odt":
+		return mediaTypeODT
+	case ".epub":
+		return mediaTypeEPUB
+	}
+
+	if archive := archiveType(doc.Data); archive != "" {
+		return archive
+	}
+	mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(doc.Data))
+	return mediaType
+}
+
+func derivedTitle(name, content string) string {
+	if name != "" {
+		return strings.TrimSuffix(path.Base(name), path.Ext(name))
+	}
+
+	line, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+	line = strings.TrimSpace(strings.TrimLeft(line, "# "))
+	if runes := []rune(line); len(runes) > maxDerivedTitleRunes {
+		line = strings.TrimSpace(string(runes[:maxDerivedTitleRunes])) + "…"
+	}
+	return line
+}
@@ -0,0 +1,174 @@
+package extractor
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type epubContainer struct {
+	Rootfiles []struct {
+		Path string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type epubPackage struct {
+	Metadata struct {
+		Titles   []string `xml:"title"`
+		Creators []string `xml:"creator"`
+		Dates    []string `xml:"date"`
+	} `xml:"metadata"`
+	Manifest []struct {
+		ID        string `xml:"id,attr"`
+		Href      string `xml:"href,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef  string `xml:"idref,attr"`
+		Linear string `xml:"linear,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// fromEPUB reads the spine in reading order; every spine document starts a chapter.
+func fromEPUB(data []byte, budget int64) (*ExtractedContent, error) {
+	a, err := openArchive(data, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	var container epubContainer
+	if err := a.decode("META-INF/container.xml", &container); err != nil {
+		return nil, err
+	}
+	if len(container.Rootfiles) == 0 {
+		return nil, fmt.Errorf("EPUB container lists no package document")
+	}
+	packagePath := container.Rootfiles[0].Path
+
+	var pkg epubPackage
+	if err := a.decode(packagePath, &pkg); err != nil {
+		return nil, err
+	}
+
+	hrefs := make(map[string]string)
+	for _, item := range pkg.Manifest {
+		if item.MediaType == "application/xhtml+xml" || item.MediaType == "text/html" {
+			hrefs[item.ID] = item.Href
+		}
+	}
+
+	var o outline
+	seen := make(map[string]bool)
+	for _, ref := range pkg.Spine {
+		href, ok := hrefs[ref.IDRef]
+		if !ok || ref.Linear == "no" {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(href); err == nil {
+			href = unescaped
+		}
+		name := path.Join(path.Dir(packagePath), href)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		chapter, err := a.read(name)
+		if err != nil {
+			return nil, err
+		}
+		title, blocks, err := htmlBlocks(chapter)
+		if err != nil {
+			return nil, err
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+
+		if blocks[0].level > 0 {
+			o.chapter(blocks[0].level, blocks[0].text)
+			blocks = blocks[1:]
+		} else {
+			o.chapter(1, title)
+		}
+		for _, b := range blocks {
+			if b.level > 0 {
+				o.heading(b.level, b.text)
+			} else {
+				o.paragraph(b.text)
+			}
+		}
+	}
+
+	return &ExtractedContent{
+		Title:       strings.TrimSpace(first(pkg.Metadata.Titles)),
+		Content:     o.content(),
+		Author:      strings.TrimSpace(strings.Join(pkg.Metadata.Creators, ", ")),
+		PublishDate: metadataDate(first(pkg.Metadata.Dates)),
+		Chapters:    o.chapters,
+	}, nil
+}
+
+// htmlBlocks splits an XHTML document into headings and paragraphs and returns
+// its <title> alongside them.
+func htmlBlocks(document []byte) (string, []block, error) {
+	root, err := html.Parse(bytes.NewReader(document))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse chapter: %w", err)
+	}
+
+	var title string
+	var blocks []block
+	var current block
+	flush := func() {
+		if text := collapseSpace(current.text); text != "" {
+			blocks = append(blocks, block{level: current.level, text: text})
+		}
+		current = block{}
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			current.text += n.Data
+			return
+		}
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Script, atom.Style:
+				return
+			case atom.Title:
+				if n.FirstChild != nil {
+					title = collapseSpace(n.FirstChild.Data)
+				}
+				return
+			case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+				flush()
+				current.level = int(n.Data[1] - '0')
+				defer flush()
+			case atom.P, atom.Div, atom.Li, atom.Blockquote, atom.Pre, atom.Tr, atom.Section, atom.Br:
+				flush()
+				defer flush()
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	flush()
+
+	return title, blocks, nil
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,83 @@
+package extractor
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const epubContainerXML = `<?xml version="1.0"?>
+<container xmlns="urn:oasis:names:tc:opendocument:xmlns:container" version="1.0">
+<rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`
+
+// epubDuplicateSpineOPF lists chapter one three times in its spine: twice by
+// the same idref and once through a second manifest item with the same file.
+const epubDuplicateSpineOPF = `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" xmlns:dc="http://purl.org/dc/elements/1.1/" version="3.0">
+<metadata><dc:title>A Short Book</dc:title><dc:creator>First Author</dc:creator><dc:creator>Second Author</dc:creator><dc:date>2021-05</dc:date></metadata>
+<manifest>
+<item id="ch1" href="text/chapter%201.xhtml" media-type="application/xhtml+xml"/>
+<item id="ch1-again" href="text/chapter 1.xhtml" media-type="application/xhtml+xml"/>
+<item id="ch2" href="text/chapter2.xhtml" media-type="application/xhtml+xml"/>
+<item id="notes" href="text/notes.xhtml" media-type="application/xhtml+xml"/>
+<item id="cover" href="cover.jpg" media-type="image/jpeg"/>
+</manifest>
+<spine><itemref idref="cover"/><itemref idref="ch1"/><itemref idref="ch1"/><itemref idref="ch2"/><itemref idref="ch1-again"/><itemref idref="notes" linear="no"/></spine>
+</package>`
+
+const epubChapterOne = `<html xmlns="http://www.w3.org/1999/xhtml"><head><title>Beginnings</title></head>
+<body><p>It started on a quiet morning.</p><h2>A detour</h2><p>Nothing went to plan.</p></body></html>`
+
+const epubChapterTwo = `<html xmlns="http://www.w3.org/1999/xhtml"><head><title>Ignored</title></head>
+<body><h1>The Middle</h1><p>Things got complicated.</p></body></html>`
+
+func buildEPUB(t *testing.T) ([]byte, int64) {
+	t.Helper()
+	entries := []archiveEntry{
+		{"mimetype", mediaTypeEPUB},
+		{"META-INF/container.xml", epubContainerXML},
+		{"OEBPS/content.opf", epubDuplicateSpineOPF},
+		{"OEBPS/text/chapter 1.xhtml", epubChapterOne},
+		{"OEBPS/text/chapter2.xhtml", epubChapterTwo},
+		{"OEBPS/text/notes.xhtml", `<html><body><p>Endnotes.</p></body></html>`},
+	}
+	// needed is exactly what reading each part once costs.
+	needed := int64(len(epubContainerXML) + len(epubDuplicateSpineOPF) + len(epubChapterOne) + len(epubChapterTwo))
+	return buildArchive(t, entries), needed
+}
+
+func TestFromEPUBReadsEachSpineDocumentOnce(t *testing.T) {
+	data, needed := buildEPUB(t)
+
+	result, err := fromEPUB(data, needed)
+	if err != nil {
+		t.Fatalf("fromEPUB returned error: %v", err)
+	}
+
+	want := "# Beginnings\n\nIt started on a quiet morning.\n\n## A detour\n\nNothing went to plan.\n\n# The Middle\n\nThings got complicated."
+	if result.Content != want {
+		t.Errorf("content = %q, want %q", result.Content, want)
+	}
+	if got := chapterTitles(t, result); !slices.Equal(got, []string{"# Beginnings", "# The Middle"}) {
+		t.Errorf("chapters start at %q, want one per distinct spine document", got)
+	}
+	if result.Title != "A Short Book" || result.Author != "First Author, Second Author" || result.PublishDate != "2021-05-01T00:00:00Z" {
+		t.Errorf("metadata = %q, %q, %q", result.Title, result.Author, result.PublishDate)
+	}
+
+	if _, err := fromEPUB(data, needed-1); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("err = %v, want ErrTooLarge one byte short of the parts' size", err)
+	}
+}
+
+func TestFromEPUBWithoutPackage(t *testing.T) {
+	data := buildArchive(t, []archiveEntry{
+		{"mimetype", mediaTypeEPUB},
+		{"META-INF/container.xml", `<container><rootfiles></rootfiles></container>`},
+	})
+	if _, err := fromEPUB(data, DefaultMaxBodyBytes); err == nil || !strings.Contains(err.Error(), "no package document") {
+		t.Errorf("err = %v, want the missing package document reported", err)
+	}
+}
@@ -0,0 +1,122 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHostConcurrency = 2
+	hostSweepInterval      = time.Minute
+)
+
+// hostLimiter bounds concurrent fetches per host and spaces out their starts.
+// It is shared by every request the extractor serves, and forgets hosts that
+// have nothing in flight and no spacing left to enforce.
+type hostLimiter struct {
+	concurrency int
+	delay       time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostSlots
+	swept time.Time
+}
+
+type hostSlots struct {
+	slots chan struct{}
+	users int
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newHostLimiter(concurrency int, delay time.Duration) *hostLimiter {
+	if concurrency < 1 {
+		concurrency = DefaultHostConcurrency
+	}
+	return &hostLimiter{
+		concurrency: concurrency,
+		delay:       delay,
+		hosts:       make(map[string]*hostSlots),
+	}
+}
+
+// wait blocks until the host has a free slot and the delay since the previous
+// request to it has passed. The longer of delay and the configured spacing
+// applies. It fails at once when that time falls after ctx's deadline. Callers
+// must call release once their request is done.
+func (l *hostLimiter) wait(ctx context.Context, host string, delay time.Duration) (release func(), err error) {
+	host = strings.ToLower(host)
+	l.mu.Lock()
+	l.sweep()
+	h, ok := l.hosts[host]
+	if !ok {
+		h = &hostSlots{slots: make(chan struct{}, l.concurrency)}
+		l.hosts[host] = h
+	}
+	h.users++
+	l.mu.Unlock()
+
+	leave := func() {
+		l.mu.Lock()
+		h.users--
+		l.mu.Unlock()
+	}
+	select {
+	case h.slots <- struct{}{}:
+	case <-ctx.Done():
+		leave()
+		return nil, ctx.Err()
+	}
+	release = func() {
+		<-h.slots
+		leave()
+	}
+
+	h.mu.Lock()
+	start := time.Now()
+	if h.next.After(start) {
+		start = h.next
+	}
+	if deadline, ok := ctx.Deadline(); ok && start.After(deadline) {
+		h.mu.Unlock()
+		release()
+		return nil, fmt.Errorf("%w: the next fetch from %s may start in %s", context.DeadlineExceeded, host, time.Until(start).Round(time.Second))
+	}
+	h.next = start.Add(max(delay, l.delay))
+	h.mu.Unlock()
+
+	if wait := time.Until(start); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// sweep drops hosts nobody is waiting on whose spacing has run out. It runs at
+// most once per hostSweepInterval and must be called with l.mu held.
+func (l *hostLimiter) sweep() {
+	now := time.Now()
+	if now.Sub(l.swept) < hostSweepInterval {
+		return
+	}
+	l.swept = now
+
+	for host, h := range l.hosts {
+		h.mu.Lock()
+		idle := h.users == 0 && !h.next.After(now)
+		h.mu.Unlock()
+		if idle {
+			delete(l.hosts, host)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package extractor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pemistahl/lingua-go"
+)
+
+// AllLanguages in a language list enables every language lingua supports.
+const AllLanguages = "all"
+
+var DefaultLanguages = []string{"en", "id", "es", "fr", "de"}
+
+type LanguageDetector struct {
+	detector lingua.LanguageDetector
+}
+
+// NewLanguageDetector detects among the given ISO 639-1 codes. Fewer languages
+// make detection faster and more accurate, so list only those you expect.
+func NewLanguageDetector(codes []string) (*LanguageDetector, error) {
+	var languages []lingua.Language
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if strings.EqualFold(code, AllLanguages) {
+			languages = lingua.AllLanguages()
+			break
+		}
+
+		language := lingua.GetLanguageFromIsoCode639_1(lingua.GetIsoCode639_1FromValue(code))
+		if language == lingua.Unknown {
+			return nil, fmt.Errorf("unknown ISO 639-1 language code %q", code)
+		}
+		languages = append(languages, language)
+	}
+	if len(languages) < 2 {
+		return nil, fmt.Errorf("language detection needs at least two languages, got %d", len(languages))
+	}
+
+	return &LanguageDetector{
+		detector: lingua.NewLanguageDetectorBuilder().FromLanguages(languages...).Build(),
+	}, nil
+}
+
+// Detect returns the ISO 639-1 code of the most likely language and its
+// confidence between 0 and 1, or an empty code when no language fits.
+func (d *LanguageDetector) Detect(text string) (string, float64) {
+	values := d.detector.ComputeLanguageConfidenceValues(text)
+	if len(values) == 0 || values[0].Value() == 0 {
+		return "", 0
+	}
+	code := strings.ToLower(values[0].Language().IsoCode639_1().String())
+	return code, values[0].Value()
+}
+
+// LanguageName returns the English name of an ISO 639-1 code, such as "German"
+// for "de", or an empty string when the code is unknown.
+func LanguageName(code string) string {
+	language := lingua.GetLanguageFromIsoCode639_1(lingua.GetIsoCode639_1FromValue(code))
+	if language == lingua.Unknown {
+		return ""
+	}
+	return language.String()
+}
@@ -0,0 +1,274 @@
+package extractor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlToMarkdown renders an HTML fragment as Markdown, keeping the headings,
+// lists, tables, code blocks and links that plain text flattens away.
+func htmlToMarkdown(fragment string) (string, error) {
+	root, err := html.Parse(strings.NewReader(fragment))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse article HTML: %w", err)
+	}
+
+	var w markdownWriter
+	w.children(root)
+	return w.markdown(), nil
+}
+
+type markdownWriter struct {
+	blocks []string
+	inline strings.Builder
+}
+
+func (w *markdownWriter) markdown() string {
+	w.flush()
+	return strings.Join(w.blocks, "\n\n")
+}
+
+func (w *markdownWriter) add(block string) {
+	w.flush()
+	if strings.TrimSpace(block) != "" {
+		w.blocks = append(w.blocks, block)
+	}
+}
+
+func (w *markdownWriter) flush() {
+	lines := strings.Split(w.inline.String(), "\n")
+	for n, line := range lines {
+		lines[n] = collapseSpace(line)
+	}
+	if text := strings.TrimSpace(strings.Join(lines, "\n")); text != "" {
+		w.blocks = append(w.blocks, text)
+	}
+	w.inline.Reset()
+}
+
+func (w *markdownWriter) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.node(c)
+	}
+}
+
+func (w *markdownWriter) node(n *html.Node) {
+	if n.Type == html.TextNode {
+		w.inline.WriteString(n.Data)
+		return
+	}
+	if n.Type != html.ElementNode {
+		w.children(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Svg, atom.Button, atom.Form, atom.Head:
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		if text := collapseSpace(inlineMarkdown(n)); text != "" {
+			w.add(strings.Repeat("#", int(n.Data[1]-'0')) + " " + text)
+		}
+	case atom.Ul, atom.Ol:
+		w.add(listMarkdown(n))
+	case atom.Table:
+		w.add(tableMarkdown(n))
+	case atom.Pre:
+		w.add(codeFence(n))
+	case atom.Blockquote:
+		var quote markdownWriter
+		quote.children(n)
+		lines := strings.Split(quote.markdown(), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		w.add(strings.Join(lines, "\n"))
+	case atom.Hr:
+		w.add("---")
+	case atom.Br:
+		w.inline.WriteString("\n")
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Main, atom.Aside,
+		atom.Figure, atom.Figcaption, atom.Dl, atom.Dt, atom.Dd, atom.Details, atom.Summary:
+		w.flush()
+		w.children(n)
+		w.flush()
+	case atom.A, atom.Strong, atom.B, atom.Em, atom.I, atom.Code, atom.Img:
+		w.inline.WriteString(inlineMarkdown(n))
+	default:
+		w.children(n)
+	}
+}
+
+func inlineMarkdown(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode:
+			b.WriteString(c.Data)
+		case c.Type != html.ElementNode:
+		case c.DataAtom == atom.Br:
+			b.WriteString("\n")
+		default:
+			b.WriteString(inlineElement(c))
+		}
+	}
+	return inlineWrap(n, b.String())
+}
+
+func inlineElement(n *html.Node) string {
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Img, atom.Svg, atom.Button:
+		return ""
+	case atom.Code:
+		return "`" + strings.TrimSpace(textContent(n)) + "`"
+	}
+	return inlineMarkdown(n)
+}
+
+// inlineWrap adds emphasis and link syntax around already rendered children.
+func inlineWrap(n *html.Node, inner string) string {
+	trimmed := strings.TrimSpace(inner)
+	if trimmed == "" {
+		return inner
+	}
+	start := strings.Index(inner, trimmed)
+	lead, trail := inner[:start], inner[start+len(trimmed):]
+
+	switch n.DataAtom {
+	case atom.Strong, atom.B:
+		return lead + "**" + trimmed + "**" + trail
+	case atom.Em, atom.I:
+		return lead + "*" + trimmed + "*" + trail
+	case atom.A:
+		href := attribute(n, "href")
+		if strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") {
+			return lead + "[" + collapseSpace(trimmed) + "](" + href + ")" + trail
+		}
+	}
+	return inner
+}
+
+func listMarkdown(list *html.Node) string {
+	number := 1
+	if start, err := strconv.Atoi(attribute(list, "start")); err == nil {
+		number = start
+	}
+
+	var items []string
+	for li := list.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+			continue
+		}
+		marker := "- "
+		if list.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+
+		var item markdownWriter
+		item.children(li)
+		item.flush()
+		lines := strings.Split(strings.Join(item.blocks, "\n"), "\n")
+		for i := 1; i < len(lines); i++ {
+			if lines[i] != "" {
+				lines[i] = strings.Repeat(" ", len(marker)) + lines[i]
+			}
+		}
+		items = append(items, marker+strings.Join(lines, "\n"))
+	}
+	return strings.Join(items, "\n")
+}
+
+func tableMarkdown(table *html.Node) string {
+	var rows [][]string
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch c.DataAtom {
+			case atom.Tr:
+				var row []string
+				for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+						var w markdownWriter
+						w.children(cell)
+						text := strings.ReplaceAll(w.markdown(), "\n", " ")
+						row = append(row, strings.ReplaceAll(text, "|", `\|`))
+					}
+				}
+				rows = append(rows, row)
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				visit(c)
+			}
+		}
+	}
+	visit(table)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func codeFence(pre *html.Node) string {
+	language := ""
+	for c := pre.FirstChild; c != nil; c = c.NextSibling {
+		if c.DataAtom != atom.Code {
+			continue
+		}
+		for _, class := range strings.Fields(attribute(c, "class")) {
+			if lang, ok := strings.CutPrefix(class, "language-"); ok {
+				language = lang
+			} else if lang, ok := strings.CutPrefix(class, "lang-"); ok {
+				language = lang
+			}
+		}
+	}
+
+	code := strings.Trim(textContent(pre), "\n")
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + language + "\n" + code + "\n" + fence
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
+
+func attribute(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
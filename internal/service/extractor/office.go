@@ -0,0 +1,225 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type docxStyles struct {
+	Styles []struct {
+		ID   string `xml:"styleId,attr"`
+		Name struct {
+			Val string `xml:"val,attr"`
+		} `xml:"name"`
+		OutlineLevel *struct {
+			Val int `xml:"val,attr"`
+		} `xml:"pPr>outlineLvl"`
+	} `xml:"style"`
+}
+
+type docxCore struct {
+	Title   string `xml:"title"`
+	Creator string `xml:"creator"`
+	Created string `xml:"created"`
+}
+
+type odtMeta struct {
+	Meta struct {
+		Title          string `xml:"title"`
+		Creator        string `xml:"creator"`
+		InitialCreator string `xml:"initial-creator"`
+		Created        string `xml:"creation-date"`
+	} `xml:"meta"`
+}
+
+func fromDOCX(data []byte, budget int64) (*ExtractedContent, error) {
+	a, err := openArchive(data, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	var styles docxStyles
+	if a.has("word/styles.xml") {
+		if err := a.decode("word/styles.xml", &styles); err != nil {
+			return nil, err
+		}
+	}
+	levels := make(map[string]int)
+	for _, style := range styles.Styles {
+		name := strings.ToLower(style.Name.Val)
+		if level, ok := strings.CutPrefix(name, "heading "); ok {
+			if n, err := strconv.Atoi(level); err == nil {
+				levels[style.ID] = n
+			}
+		} else if style.OutlineLevel != nil && style.OutlineLevel.Val < 9 {
+			levels[style.ID] = style.OutlineLevel.Val + 1
+		}
+	}
+
+	document, err := a.read("word/document.xml")
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := docxBlocks(document, levels)
+	if err != nil {
+		return nil, err
+	}
+
+	var core docxCore
+	if a.has("docProps/core.xml") {
+		if err := a.decode("docProps/core.xml", &core); err != nil {
+			return nil, err
+		}
+	}
+
+	var o outline
+	o.blocks(blocks)
+	return &ExtractedContent{
+		Title:       strings.TrimSpace(core.Title),
+		Content:     o.content(),
+		Author:      strings.TrimSpace(core.Creator),
+		PublishDate: metadataDate(core.Created),
+		Chapters:    o.chapters,
+	}, nil
+}
+
+func docxBlocks(document []byte, levels map[string]int) ([]block, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	var blocks []block
+	var current block
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return blocks, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse word/document.xml: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				current = block{}
+				text.Reset()
+			case "pStyle":
+				current.level = levels[attr(t, "val")]
+			case "outlineLvl":
+				if n, err := strconv.Atoi(attr(t, "val")); err == nil && n < 9 {
+					current.level = n + 1
+				}
+			case "t":
+				var s string
+				if err := decoder.DecodeElement(&s, &t); err != nil {
+					return nil, fmt.Errorf("failed to parse word/document.xml: %w", err)
+				}
+				text.WriteString(s)
+			case "tab":
+				text.WriteString("\t")
+			case "br", "cr":
+				text.WriteString("\n")
+			}
+		case xml.EndElement:
+			if t.Name.Local == "p" {
+				current.text = text.String()
+				blocks = append(blocks, current)
+			}
+		}
+	}
+}
+
+func fromODT(data []byte, budget int64) (*ExtractedContent, error) {
+	a, err := openArchive(data, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := a.read("content.xml")
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := odtBlocks(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta odtMeta
+	if a.has("meta.xml") {
+		if err := a.decode("meta.xml", &meta); err != nil {
+			return nil, err
+		}
+	}
+	author := meta.Meta.InitialCreator
+	if author == "" {
+		author = meta.Meta.Creator
+	}
+
+	var o outline
+	o.blocks(blocks)
+	return &ExtractedContent{
+		Title:       strings.TrimSpace(meta.Meta.Title),
+		Content:     o.content(),
+		Author:      strings.TrimSpace(author),
+		PublishDate: metadataDate(meta.Meta.Created),
+		Chapters:    o.chapters,
+	}, nil
+}
+
+// odtBlocks keeps a stack of open paragraphs because ODF nests them, for
+// example inside footnotes and frames.
+func odtBlocks(content []byte) ([]block, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	var blocks []block
+	var open []*block
+	write := func(s string) {
+		if len(open) > 0 {
+			open[len(open)-1].text += s
+		}
+	}
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return blocks, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse content.xml: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				open = append(open, &block{})
+			case "h":
+				level, err := strconv.Atoi(attr(t, "outline-level"))
+				if err != nil || level < 1 {
+					level = 1
+				}
+				open = append(open, &block{level: level})
+			case "s":
+				count, err := strconv.Atoi(attr(t, "c"))
+				if err != nil || count < 1 {
+					count = 1
+				}
+				write(strings.Repeat(" ", count))
+			case "tab":
+				write("\t")
+			case "line-break":
+				write("\n")
+			}
+		case xml.CharData:
+			write(string(t))
+		case xml.EndElement:
+			if (t.Name.Local == "p" || t.Name.Local == "h") && len(open) > 0 {
+				blocks = append(blocks, *open[len(open)-1])
+				open = open[:len(open)-1]
+			}
+		}
+	}
+}
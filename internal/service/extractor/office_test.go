@@ -0,0 +1,108 @@
+package extractor
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// chapterTitles reads each chapter's heading back from the content at its offset.
+func chapterTitles(t *testing.T, result *ExtractedContent) []string {
+	t.Helper()
+	var titles []string
+	for _, offset := range result.ChapterOffsets() {
+		line, _, _ := strings.Cut(result.ModelText()[offset:], "\n")
+		titles = append(titles, line)
+	}
+	return titles
+}
+
+func TestFromDOCXHeadingsBecomeChapters(t *testing.T) {
+	styles := `<w:styles xmlns:w="w">
+<w:style w:styleId="Heading1"><w:name w:val="heading 1"/></w:style>
+<w:style w:styleId="Heading2"><w:name w:val="Heading 2"/></w:style>
+<w:style w:styleId="Part"><w:name w:val="Part Title"/><w:pPr><w:outlineLvl w:val="0"/></w:pPr></w:style>
+</w:styles>`
+	paragraph := func(style, text string) string {
+		props := ""
+		if style != "" {
+			props = `<w:pPr><w:pStyle w:val="` + style + `"/></w:pPr>`
+		}
+		return `<w:p>` + props + `<w:r><w:t>` + text + `</w:t></w:r></w:p>`
+	}
+	document := `<w:document xmlns:w="w"><w:body>` +
+		paragraph("", "Preface before any heading.") +
+		paragraph("Heading1", "Introduction") +
+		paragraph("", "Why this report exists.") +
+		paragraph("Heading2", "Scope") +
+		paragraph("", "What it covers.") +
+		paragraph("Part", "Results") +
+		`<w:p><w:r><w:t>Revenue</w:t><w:tab/><w:t>grew.</w:t></w:r></w:p>` +
+		`</w:body></w:document>`
+	core := `<cp:coreProperties xmlns:cp="cp" xmlns:dc="dc" xmlns:dcterms="dcterms">
+<dc:title>Annual Report</dc:title><dc:creator>Jane Doe</dc:creator><dcterms:created>2024-01-31T12:00:00Z</dcterms:created>
+</cp:coreProperties>`
+
+	result, err := fromDOCX(buildArchive(t, []archiveEntry{
+		{"word/styles.xml", styles},
+		{"word/document.xml", document},
+		{"docProps/core.xml", core},
+	}), DefaultMaxBodyBytes)
+	if err != nil {
+		t.Fatalf("fromDOCX returned error: %v", err)
+	}
+
+	want := "Preface before any heading.\n\n# Introduction\n\nWhy this report exists.\n\n## Scope\n\nWhat it covers.\n\n# Results\n\nRevenue\tgrew."
+	if result.Content != want {
+		t.Errorf("content = %q, want %q", result.Content, want)
+	}
+	if got := chapterTitles(t, result); !slices.Equal(got, []string{"# Introduction", "# Results"}) {
+		t.Errorf("chapters start at %q, want the two top-level headings", got)
+	}
+	if result.Title != "Annual Report" || result.Author != "Jane Doe" || result.PublishDate != "2024-01-31T12:00:00Z" {
+		t.Errorf("metadata = %q, %q, %q", result.Title, result.Author, result.PublishDate)
+	}
+}
+
+func TestFromODTHeadingsBecomeChapters(t *testing.T) {
+	content := `<office:document-content xmlns:office="office" xmlns:text="text"><office:body><office:text>
+<text:h text:outline-level="2">Background</text:h>
+<text:p>Two<text:s text:c="3"/>spaces and a<text:line-break/>break.</text:p>
+<text:h text:outline-level="3">Detail</text:h>
+<text:p>Body with a note<text:note><text:note-body><text:p>Footnote text.</text:p></text:note-body></text:note> inside.</text:p>
+<text:h text:outline-level="2">Findings</text:h>
+<text:p>Last paragraph.</text:p>
+</office:text></office:body></office:document-content>`
+	meta := `<office:document-meta xmlns:office="office" xmlns:meta="meta" xmlns:dc="dc"><office:meta>
+<dc:title>Field Notes</dc:title><meta:initial-creator>Ana Silva</meta:initial-creator><dc:creator>Editor</dc:creator>
+<meta:creation-date>2023-06-01T08:30:00</meta:creation-date>
+</office:meta></office:document-meta>`
+
+	result, err := fromODT(buildArchive(t, []archiveEntry{
+		{"mimetype", mediaTypeODT},
+		{"content.xml", content},
+		{"meta.xml", meta},
+	}), DefaultMaxBodyBytes)
+	if err != nil {
+		t.Fatalf("fromODT returned error: %v", err)
+	}
+
+	for _, want := range []string{"## Background", "Two   spaces and a\nbreak.", "### Detail", "Footnote text.", "## Findings"} {
+		if !strings.Contains(result.Content, want) {
+			t.Errorf("content = %q, want it to contain %q", result.Content, want)
+		}
+	}
+	if got := chapterTitles(t, result); !slices.Equal(got, []string{"## Background", "## Findings"}) {
+		t.Errorf("chapters start at %q, want the two outline level 2 headings", got)
+	}
+	if result.Title != "Field Notes" || result.Author != "Ana Silva" || result.PublishDate != "2023-06-01T08:30:00Z" {
+		t.Errorf("metadata = %q, %q, %q", result.Title, result.Author, result.PublishDate)
+	}
+}
+
+func TestChapterOffsetsIgnoreMarkdown(t *testing.T) {
+	result := &ExtractedContent{Content: "text", Markdown: "# text", Chapters: []Chapter{{Title: "text"}}}
+	if offsets := result.ChapterOffsets(); offsets != nil {
+		t.Errorf("ChapterOffsets = %v, want none for Markdown, whose offsets differ from Content", offsets)
+	}
+}
@@ -0,0 +1,100 @@
+package extractor
+
+import (
+	"strings"
+	"time"
+)
+
+// Chapter marks where a chapter of a long document starts in ExtractedContent.Content.
+type Chapter struct {
+	Title  string
+	Offset int
+}
+
+// block is a paragraph of document text, or a heading when level is above zero.
+type block struct {
+	level int
+	text  string
+}
+
+// outline assembles document text block by block, rendering headings as
+// Markdown so the model sees the document's structure.
+type outline struct {
+	text     strings.Builder
+	chapters []Chapter
+}
+
+// blocks appends document blocks, treating the highest heading level used as chapters.
+func (o *outline) blocks(blocks []block) {
+	top := 0
+	for _, b := range blocks {
+		if b.level > 0 && (top == 0 || b.level < top) {
+			top = b.level
+		}
+	}
+
+	for _, b := range blocks {
+		switch {
+		case b.level == 0:
+			o.paragraph(b.text)
+		case b.level == top:
+			o.chapter(b.level, b.text)
+		default:
+			o.heading(b.level, b.text)
+		}
+	}
+}
+
+func (o *outline) heading(level int, title string) {
+	title = collapseSpace(title)
+	if title == "" {
+		return
+	}
+	level = min(max(level, 1), 6)
+	o.block(strings.Repeat("#", level) + " " + title)
+}
+
+// chapter starts a new chapter, writing its title as a heading when it has one.
+func (o *outline) chapter(level int, title string) {
+	title = collapseSpace(title)
+	o.separate()
+	o.chapters = append(o.chapters, Chapter{Title: title, Offset: o.text.Len()})
+	o.heading(level, title)
+}
+
+func (o *outline) paragraph(text string) {
+	o.block(strings.TrimSpace(text))
+}
+
+func (o *outline) block(text string) {
+	if text == "" {
+		return
+	}
+	o.separate()
+	o.text.WriteString(text)
+}
+
+func (o *outline) separate() {
+	if o.text.Len() > 0 && !strings.HasSuffix(o.text.String(), "\n\n") {
+		o.text.WriteString("\n\n")
+	}
+}
+
+func (o *outline) content() string {
+	return strings.TrimSpace(o.text.String())
+}
+
+func collapseSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// metadataDate normalizes the dates found in document metadata to RFC 3339.
+func metadataDate(value string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02", "2006-01", "2006"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(time.RFC3339)
+		}
+	}
+	return ""
+}
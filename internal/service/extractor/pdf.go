@@ -0,0 +1,105 @@
+package extractor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// pageBreak separates pages in extracted PDF text. The form feed marks the
+// boundary and the surrounding newlines keep it a paragraph break for chunking.
+const pageBreak = "\n\f\n"
+
+var (
+	ErrEncryptedPDF = errors.New("PDF is encrypted")
+	ErrImageOnlyPDF = errors.New("PDF has no extractable text; it may be scanned images")
+)
+
+func isPDF(mediaType string, body []byte) bool {
+	return mediaType == "application/pdf" || bytes.HasPrefix(body, []byte("%PDF-"))
+}
+
+func fromPDF(data []byte) (result *ExtractedContent, err error) {
+	// The PDF reader panics on malformed input instead of returning errors.
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("failed to parse PDF: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		if errors.Is(err, pdf.ErrInvalidPassword) || strings.Contains(err.Error(), "encryption") {
+			return nil, fmt.Errorf("%w: %v", ErrEncryptedPDF, err)
+		}
+		return nil, fmt.Errorf("failed to parse PDF: %w", err)
+	}
+
+	pages := make([]string, 0, reader.NumPage())
+	hasText := false
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		text := pageText(page.Content().Text)
+		hasText = hasText || text != ""
+		pages = append(pages, text)
+	}
+	if !hasText {
+		return nil, ErrImageOnlyPDF
+	}
+
+	info := reader.Trailer().Key("Info")
+	return &ExtractedContent{
+		Title:       strings.TrimSpace(info.Key("Title").Text()),
+		Content:     strings.Join(pages, pageBreak),
+		Author:      strings.TrimSpace(info.Key("Author").Text()),
+		PublishDate: pdfDate(info.Key("CreationDate").Text()),
+	}, nil
+}
+
+// pageText rebuilds lines and words from positioned glyphs, since PDFs rarely
+// encode the spaces and line breaks a reader sees.
+func pageText(glyphs []pdf.Text) string {
+	var text strings.Builder
+	var prev pdf.Text
+	for n, glyph := range glyphs {
+		if n > 0 {
+			size := math.Max(prev.FontSize, 1)
+			switch {
+			case math.Abs(glyph.Y-prev.Y) > 1.5*size:
+				text.WriteString("\n\n")
+			case math.Abs(glyph.Y-prev.Y) > size/2:
+				text.WriteString("\n")
+			case glyph.X-(prev.X+prev.W) > size/5 && prev.S != " " && glyph.S != " ":
+				text.WriteString(" ")
+			}
+		}
+		text.WriteString(glyph.S)
+		prev = glyph
+	}
+
+	lines := strings.Split(text.String(), "\n")
+	for n, line := range lines {
+		lines[n] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// pdfDate converts a PDF date string such as "D:20240131120000+07'00'" to RFC 3339.
+func pdfDate(value string) string {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "D:")
+	value = strings.ReplaceAll(value, "'", "")
+	for _, layout := range []string{"20060102150405Z0700", "20060102150405Z", "20060102150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(time.RFC3339)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package extractor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildPDF writes a minimal PDF with one Helvetica text line per entry on each
+// page. A page without lines has no text at all, like a scanned image.
+func buildPDF(pages [][]string, encrypt bool) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+	var kids []string
+	for _, lines := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", len(objects)+1))
+		objects = append(objects, fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			len(objects)+2))
+
+		ops := "q 1 0 0 1 0 0 cm Q"
+		if len(lines) > 0 {
+			ops = "BT /F1 12 Tf 72 720 Td 14 TL\n"
+			for _, line := range lines {
+				ops += "(" + line + ") Tj T*\n"
+			}
+			ops += "ET"
+		}
+		objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(ops), ops))
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	objects = append(objects, "<< /Title (Quarterly Report) /Author (Jane Doe) /CreationDate (D:20240131120000Z) >>")
+	trailer := fmt.Sprintf("/Info %d 0 R", len(objects))
+	if encrypt {
+		objects = append(objects, "<< /Filter /Custom /V 9 >>")
+		trailer += fmt.Sprintf(" /Encrypt %d 0 R /ID [<00> <00>]", len(objects))
+	}
+
+	var out strings.Builder
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R %s >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, trailer, xref)
+	return []byte(out.String())
+}
+
+func TestFromPDF(t *testing.T) {
+	data := buildPDF([][]string{
+		{"Revenue grew twenty percent this quarter.", "Costs were flat."},
+		{"Page two covers the outlook."},
+	}, false)
+
+	result, err := fromPDF(data)
+	if err != nil {
+		t.Fatalf("fromPDF returned error: %v", err)
+	}
+	want := "Revenue grew twenty percent this quarter.\nCosts were flat." + pageBreak + "Page two covers the outlook."
+	if result.Content != want {
+		t.Errorf("content = %q, want %q", result.Content, want)
+	}
+	if result.Title != "Quarterly Report" || result.Author != "Jane Doe" || result.PublishDate != "2024-01-31T12:00:00Z" {
+		t.Errorf("metadata = %q, %q, %q", result.Title, result.Author, result.PublishDate)
+	}
+}
+
+func TestFromPDFErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"image only", buildPDF([][]string{nil, nil}, false), ErrImageOnlyPDF},
+		{"encrypted", buildPDF([][]string{{"secret"}}, true), ErrEncryptedPDF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := fromPDF(tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := fromPDF([]byte("%PDF-1.4\nnot really a PDF")); err == nil {
+		t.Errorf("fromPDF accepted a malformed PDF")
+	}
+}
+
+func TestPDFDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"D:20240131120000+07'00'", "2024-01-31T12:00:00+07:00"},
+		{"D:20240131120000Z", "2024-01-31T12:00:00Z"},
+		{"20240131", "2024-01-31T00:00:00Z"},
+		{"yesterday", ""},
+	}
+	for _, tt := range tests {
+		if got := pdfDate(tt.value); got != tt.want {
+			t.Errorf("pdfDate(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
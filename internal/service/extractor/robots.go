@@ -0,0 +1,159 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/temoto/robotstxt"
+)
+
+const maxRobotsBytes = 512 << 10
+
+var ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
+
+var allowAllRobots, _ = robotstxt.FromString("")
+
+// robotsCache keeps each host's robots.txt for a TTL. Concurrent lookups for a
+// host share one fetch, and expired entries are dropped once per TTL.
+type robotsCache struct {
+	client        *http.Client
+	userAgent     string
+	ttl           time.Duration
+	maxCrawlDelay time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*robotsEntry
+	swept   time.Time
+}
+
+type robotsEntry struct {
+	done    chan struct{}
+	data    *robotstxt.RobotsData
+	expires time.Time
+}
+
+func newRobotsCache(client *http.Client, userAgent string, ttl, maxCrawlDelay time.Duration) *robotsCache {
+	return &robotsCache{
+		client:        client,
+		userAgent:     userAgent,
+		ttl:           ttl,
+		maxCrawlDelay: maxCrawlDelay,
+		entries:       make(map[string]*robotsEntry),
+	}
+}
+
+// productToken is the part of a User-Agent that robots.txt groups are matched
+// against, such as "summarizerbot" for "SummarizerBot/1.0 (+https://...)".
+func productToken(userAgent string) string {
+	token, _, _ := strings.Cut(strings.TrimSpace(userAgent), "/")
+	token, _, _ = strings.Cut(token, " ")
+	return strings.ToLower(token)
+}
+
+// check returns the host's Crawl-delay for userAgent, capped at maxCrawlDelay,
+// or ErrDisallowedByRobots when the URL may not be fetched.
+func (r *robotsCache) check(ctx context.Context, u *url.URL, userAgent string) (time.Duration, error) {
+	data, err := r.lookup(ctx, u.Scheme+"://"+u.Host)
+	if err != nil {
+		return 0, err
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	agent := productToken(userAgent)
+	if !data.TestAgent(path, agent) {
+		return 0, fmt.Errorf("%w: %s", ErrDisallowedByRobots, u.Redacted())
+	}
+	return min(data.FindGroup(agent).CrawlDelay, r.maxCrawlDelay), nil
+}
+
+func (r *robotsCache) lookup(ctx context.Context, origin string) (*robotstxt.RobotsData, error) {
+	r.mu.Lock()
+	r.sweep()
+	entry, ok := r.entries[origin]
+	stale := ok && entry.fetched() && time.Now().After(entry.expires)
+	if !ok || stale {
+		entry = &robotsEntry{done: make(chan struct{})}
+		r.entries[origin] = entry
+		go r.fetch(context.WithoutCancel(ctx), origin, entry)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-entry.done:
+		return entry.data, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// sweep must be called with r.mu held.
+func (r *robotsCache) sweep() {
+	now := time.Now()
+	if now.Sub(r.swept) < r.ttl {
+		return
+	}
+	r.swept = now
+
+	for origin, entry := range r.entries {
+		if entry.fetched() && now.After(entry.expires) {
+			delete(r.entries, origin)
+		}
+	}
+}
+
+func (e *robotsEntry) fetched() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// fetch treats an unreachable or unparsable robots.txt as allowing everything;
+// the status code rules (4xx allows, 5xx disallows) come from robotstxt.
+func (r *robotsCache) fetch(ctx context.Context, origin string, entry *robotsEntry) {
+	defer close(entry.done)
+	entry.data = allowAllRobots
+	entry.expires = time.Now().Add(r.ttl)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		log.Printf("Skipping robots.txt for %s: %v", origin, err)
+		return
+	}
+	req.Header.Set("User-Agent", r.userAgent)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		log.Printf("Skipping robots.txt for %s: %v", origin, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsBytes))
+	if err != nil {
+		log.Printf("Skipping robots.txt for %s: %v", origin, err)
+		return
+	}
+	data, err := robotstxt.FromStatusAndBytes(resp.StatusCode, body)
+	if err != nil {
+		log.Printf("Skipping robots.txt for %s: %v", origin, err)
+		return
+	}
+	entry.data = data
+}
@@ -0,0 +1,74 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRobotsCrawlDelayIsCapped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("User-agent: slowbot\nCrawl-delay: 86400\n\nUser-agent: *\nCrawl-delay: 2\nDisallow: /private\n"))
+	}))
+	defer server.Close()
+
+	robots := newRobotsCache(server.Client(), "test", time.Hour, 10*time.Second)
+	tests := []struct {
+		path      string
+		userAgent string
+		want      time.Duration
+		err       error
+	}{
+		{"/page", "SlowBot/1.0", 10 * time.Second, nil},
+		{"/page", "OtherBot/1.0", 2 * time.Second, nil},
+		{"/private/page", "OtherBot/1.0", 0, ErrDisallowedByRobots},
+	}
+	for _, tt := range tests {
+		target, _ := url.Parse(server.URL + tt.path)
+		delay, err := robots.check(context.Background(), target, tt.userAgent)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("check(%s, %s) err = %v, want %v", tt.path, tt.userAgent, err, tt.err)
+		}
+		if delay != tt.want {
+			t.Errorf("check(%s, %s) = %s, want %s", tt.path, tt.userAgent, delay, tt.want)
+		}
+	}
+}
+
+func TestHostLimiterFailsFastPastDeadline(t *testing.T) {
+	limiter := newHostLimiter(1, 0)
+	release, err := limiter.wait(context.Background(), "example.com", time.Hour)
+	if err != nil {
+		t.Fatalf("first wait returned error: %v", err)
+	}
+	release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	started := time.Now()
+	if _, err := limiter.wait(ctx, "example.com", 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("wait took %s, want it to fail without waiting for the deadline", elapsed)
+	}
+
+	// The refused caller neither keeps the slot nor pushes back the next start.
+	h := limiter.hosts["example.com"]
+	if len(h.slots) != 0 || h.users != 0 {
+		t.Errorf("refused wait left %d slots and %d users behind", len(h.slots), h.users)
+	}
+	if h.next.After(started.Add(time.Hour)) {
+		t.Errorf("refused wait moved the next start to %s", h.next)
+	}
+
+	release, err = limiter.wait(context.Background(), "other.example", 0)
+	if err != nil {
+		t.Fatalf("wait on another host returned error: %v", err)
+	}
+	release()
+}
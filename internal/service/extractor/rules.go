@@ -0,0 +1,117 @@
+package extractor
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	"anpurnama/summarizer-backend/internal/repository"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ruleOverrides are the values a domain rule's selectors pick out of the page,
+// applied over readability's guesses.
+type ruleOverrides struct {
+	title  string
+	author string
+}
+
+// applyRule rewrites a page before readability sees it: excluded elements are
+// removed and, when the include selector matches, only its matches are kept in
+// the body.
+func applyRule(body []byte, rule *repository.DomainRule) ([]byte, ruleOverrides, error) {
+	var overrides ruleOverrides
+	root, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, overrides, fmt.Errorf("failed to parse webpage for domain rule: %w", err)
+	}
+
+	if overrides.title, err = selectText(root, rule.TitleSelector); err != nil {
+		return nil, overrides, err
+	}
+	if overrides.author, err = selectText(root, rule.AuthorSelector); err != nil {
+		return nil, overrides, err
+	}
+
+	excluded, err := selectAll(root, rule.ExcludeSelector)
+	if err != nil {
+		return nil, overrides, err
+	}
+	for _, n := range excluded {
+		if n.Parent != nil {
+			n.Parent.RemoveChild(n)
+		}
+	}
+
+	included, err := selectAll(root, rule.IncludeSelector)
+	if err != nil {
+		return nil, overrides, err
+	}
+	if bodyNode := findBody(root); bodyNode != nil && len(included) > 0 {
+		kept := outermost(included)
+		for _, n := range kept {
+			n.Parent.RemoveChild(n)
+		}
+		for bodyNode.FirstChild != nil {
+			bodyNode.RemoveChild(bodyNode.FirstChild)
+		}
+		for _, n := range kept {
+			bodyNode.AppendChild(n)
+		}
+	}
+
+	var rendered bytes.Buffer
+	if err := html.Render(&rendered, root); err != nil {
+		return nil, overrides, fmt.Errorf("failed to render webpage for domain rule: %w", err)
+	}
+	return rendered.Bytes(), overrides, nil
+}
+
+func selectAll(root *html.Node, selector *string) ([]*html.Node, error) {
+	if selector == nil || *selector == "" {
+		return nil, nil
+	}
+	sel, err := cascadia.Compile(*selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid domain rule selector %q: %w", *selector, err)
+	}
+	return sel.MatchAll(root), nil
+}
+
+func selectText(root *html.Node, selector *string) (string, error) {
+	nodes, err := selectAll(root, selector)
+	if err != nil || len(nodes) == 0 {
+		return "", err
+	}
+	return collapseSpace(textContent(nodes[0])), nil
+}
+
+// outermost drops nodes nested inside another matched node, keeping document order.
+func outermost(nodes []*html.Node) []*html.Node {
+	var kept []*html.Node
+	for _, n := range nodes {
+		nested := false
+		for p := n.Parent; p != nil && !nested; p = p.Parent {
+			nested = slices.Contains(nodes, p)
+		}
+		if !nested {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
+
+func findBody(n *html.Node) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == atom.Body {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if body := findBody(c); body != nil {
+			return body
+		}
+	}
+	return nil
+}
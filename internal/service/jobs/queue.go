@@ -0,0 +1,223 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"anpurnama/summarizer-backend/internal/repository"
+	"anpurnama/summarizer-backend/internal/service"
+	"anpurnama/summarizer-backend/internal/service/extractor"
+)
+
+const (
+	maxAttempts  = 3
+	pollInterval = 5 * time.Second
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobFinished = errors.New("job already finished")
+)
+
+// jobOptions is stored in a job's options column. Text and uploaded files have no
+// URL to fetch again, so their document travels with the options.
+type jobOptions struct {
+	service.SummarizeOptions
+	Document *jobDocument `json:"document,omitempty"`
+}
+
+type jobDocument struct {
+	Name        string `json:"name,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Data        []byte `json:"data"`
+}
+
+type Queue struct {
+	jobRepo  repository.JobRepository
+	pipeline *service.Pipeline
+	workers  int
+	wake     chan struct{}
+
+	mu      sync.Mutex
+	running map[int]context.CancelFunc
+}
+
+func NewQueue(jobRepo repository.JobRepository, pipeline *service.Pipeline, workers int) *Queue {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &Queue{
+		jobRepo:  jobRepo,
+		pipeline: pipeline,
+		workers:  workers,
+		wake:     make(chan struct{}, workers),
+		running:  make(map[int]context.CancelFunc),
+	}
+}
+
+func (q *Queue) Start(ctx context.Context) error {
+	if err := q.jobRepo.RequeueRunning(ctx, maxAttempts); err != nil {
+		return err
+	}
+
+	for i := 0; i < q.workers; i++ {
+		go q.work(ctx)
+	}
+	return nil
+}
+
+func (q *Queue) Enqueue(ctx context.Context, input service.SummarizeInput) (*repository.Job, error) {
+	job := &repository.Job{URL: input.Source()}
+	if input.Style != "" {
+		job.Style = &input.Style
+	}
+	if input.APIKey != "" {
+		job.APIKey = &input.APIKey
+	}
+
+	stored := jobOptions{SummarizeOptions: input.Options}
+	if doc := input.Document; doc != nil {
+		stored.Document = &jobDocument{Name: doc.Name, ContentType: doc.ContentType, Title: doc.Title, Data: doc.Data}
+	}
+	options, err := json.Marshal(stored)
+	if err != nil {
+		return nil, err
+	}
+	if string(options) != "{}" {
+		encoded := string(options)
+		job.Options = &encoded
+	}
+
+	if err := q.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+func (q *Queue) Cancel(ctx context.Context, id int) (*repository.Job, error) {
+	job, err := q.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrJobNotFound
+	}
+
+	cancelled, err := q.jobRepo.Cancel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return job, ErrJobFinished
+	}
+
+	q.mu.Lock()
+	if cancel, ok := q.running[id]; ok {
+		cancel()
+	}
+	q.mu.Unlock()
+
+	return q.jobRepo.GetByID(ctx, id)
+}
+
+func (q *Queue) work(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		job, err := q.jobRepo.ClaimNext(ctx)
+		if err != nil {
+			log.Printf("Failed to claim job: %v", err)
+		}
+		if job != nil {
+			q.process(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (q *Queue) process(ctx context.Context, job *repository.Job) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	q.mu.Lock()
+	q.running[job.ID] = cancel
+	q.mu.Unlock()
+
+	defer func() {
+		q.mu.Lock()
+		delete(q.running, job.ID)
+		q.mu.Unlock()
+	}()
+
+	current, err := q.jobRepo.GetByID(ctx, job.ID)
+	if err != nil || current == nil || current.Status != repository.JobStatusRunning {
+		return
+	}
+
+	input := service.SummarizeInput{URL: job.URL}
+	if job.Style != nil {
+		input.Style = *job.Style
+	}
+	if job.APIKey != nil {
+		input.APIKey = *job.APIKey
+	}
+	if job.Options != nil {
+		var stored jobOptions
+		if err := json.Unmarshal([]byte(*job.Options), &stored); err != nil {
+			if err := q.jobRepo.Fail(ctx, job.ID, "invalid job options: "+err.Error()); err != nil {
+				log.Printf("Failed to mark job %d as failed: %v", job.ID, err)
+			}
+			return
+		}
+		input.Options = stored.SummarizeOptions
+		if doc := stored.Document; doc != nil {
+			input.URL = ""
+			input.Document = &extractor.Document{Name: doc.Name, ContentType: doc.ContentType, Title: doc.Title, Data: doc.Data}
+		}
+	}
+
+	start := time.Now()
+	result, err := q.pipeline.Run(jobCtx, input, service.Progress{
+		OnStage: func(stage service.Stage) {
+			if err := q.jobRepo.UpdateStage(ctx, job.ID, string(stage)); err != nil {
+				log.Printf("Failed to update stage of job %d: %v", job.ID, err)
+			}
+		},
+	})
+	if jobCtx.Err() != nil && ctx.Err() == nil {
+		log.Printf("Job %d cancelled after %s", job.ID, time.Since(start))
+		return
+	}
+	if err != nil {
+		log.Printf("Job %d failed after %s: %v", job.ID, time.Since(start), err)
+		if err := q.jobRepo.Fail(ctx, job.ID, err.Error()); err != nil {
+			log.Printf("Failed to mark job %d as failed: %v", job.ID, err)
+		}
+		return
+	}
+
+	if err := q.jobRepo.Complete(ctx, job.ID, result.History.ID); err != nil {
+		log.Printf("Failed to mark job %d as completed: %v", job.ID, err)
+		return
+	}
+	log.Printf("Job %d completed in %s", job.ID, time.Since(start))
+}
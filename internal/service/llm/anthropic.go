@@ -0,0 +1,185 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	anthropicVersion          = "2023-06-01"
+	anthropicDefaultMaxTokens = 4096
+)
+
+type Anthropic struct {
+	baseURL string
+	apiKey  string
+	clients httpClients
+}
+
+type anthropicRequest struct {
+	Model     string    `json:"model"`
+	System    string    `json:"system,omitempty"`
+	Messages  []Message `json:"messages"`
+	MaxTokens int       `json:"max_tokens"`
+	Stream    bool      `json:"stream,omitempty"`
+}
+
+type anthropicResponse struct {
+	Model   string             `json:"model"`
+	Content []anthropicContent `json:"content"`
+	Usage   anthropicUsage     `json:"usage"`
+	Error   *anthropicError    `json:"error,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type anthropicStreamEvent struct {
+	Type    string             `json:"type"`
+	Message *anthropicResponse `json:"message,omitempty"`
+	Delta   *anthropicContent  `json:"delta,omitempty"`
+	Usage   *anthropicUsage    `json:"usage,omitempty"`
+	Error   *anthropicError    `json:"error,omitempty"`
+}
+
+type anthropicError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+func NewAnthropic(config Config) *Anthropic {
+	return &Anthropic{
+		baseURL: strings.TrimSuffix(config.BaseURL, "/"),
+		apiKey:  config.APIKey,
+		clients: newHTTPClients(config),
+	}
+}
+
+func (p *Anthropic) Name() string {
+	return ProviderAnthropic
+}
+
+func (p *Anthropic) Complete(ctx context.Context, req Request) (*Completion, error) {
+	resp, err := p.clients.post(ctx, false, p.baseURL+"/v1/messages", p.headers(), p.request(req, false))
+	if err != nil {
+		return nil, err
+	}
+
+	var body anthropicResponse
+	if err := decodeJSON(resp, &body); err != nil {
+		return nil, err
+	}
+
+	if body.Error != nil {
+		return nil, fmt.Errorf("anthropic API error: %s", body.Error.Message)
+	}
+
+	var text strings.Builder
+	for _, content := range body.Content {
+		if content.Type == "text" {
+			text.WriteString(content.Text)
+		}
+	}
+
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("no response received from %s", ProviderAnthropic)
+	}
+
+	return &Completion{
+		Text:  text.String(),
+		Model: modelOrDefault(body.Model, req.Model),
+		Usage: Usage{PromptTokens: body.Usage.InputTokens, CompletionTokens: body.Usage.OutputTokens},
+	}, nil
+}
+
+func (p *Anthropic) Stream(ctx context.Context, req Request, onDelta func(string) error) (*Completion, error) {
+	headers := p.headers()
+	headers["Accept"] = "text/event-stream"
+
+	resp, err := p.clients.post(ctx, true, p.baseURL+"/v1/messages", headers, p.request(req, true))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var text strings.Builder
+	var usage Usage
+	model := req.Model
+	err = readServerSentEvents(resp.Body, func(_, data string) (bool, error) {
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return false, fmt.Errorf("failed to unmarshal stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				model = modelOrDefault(event.Message.Model, model)
+				usage.PromptTokens = event.Message.Usage.InputTokens
+			}
+		case "message_delta":
+			if event.Usage != nil {
+				usage.CompletionTokens = event.Usage.OutputTokens
+			}
+		case "content_block_delta":
+			if event.Delta == nil || event.Delta.Text == "" {
+				return false, nil
+			}
+			text.WriteString(event.Delta.Text)
+			return false, onDelta(event.Delta.Text)
+		case "error":
+			if event.Error != nil {
+				return false, fmt.Errorf("anthropic API error: %s", event.Error.Message)
+			}
+			return false, fmt.Errorf("anthropic API error")
+		case "message_stop":
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("no response received from %s", ProviderAnthropic)
+	}
+
+	return &Completion{Text: text.String(), Model: model, Usage: usage}, nil
+}
+
+func (p *Anthropic) request(req Request, stream bool) anthropicRequest {
+	body := anthropicRequest{
+		Model:     req.Model,
+		MaxTokens: req.MaxTokens,
+		Stream:    stream,
+	}
+	if body.MaxTokens <= 0 {
+		body.MaxTokens = anthropicDefaultMaxTokens
+	}
+
+	for _, message := range req.Messages {
+		if message.Role == "system" {
+			body.System = message.Content
+			continue
+		}
+		body.Messages = append(body.Messages, message)
+	}
+	return body
+}
+
+func (p *Anthropic) headers() map[string]string {
+	return map[string]string{
+		"x-api-key":         p.apiKey,
+		"anthropic-version": anthropicVersion,
+	}
+}
@@ -0,0 +1,113 @@
+package llm
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAnthropicComplete(t *testing.T) {
+	server, recorded := newStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"model":"claude-test-20250101","content":[
+			{"type":"text","text":"First part. "},{"type":"tool_use"},{"type":"text","text":"Second part."}],
+			"usage":{"input_tokens":200,"output_tokens":50}}`)
+	})
+
+	provider := NewAnthropic(Config{BaseURL: server.URL, APIKey: "secret"})
+	completion, err := provider.Complete(context.Background(), Request{
+		Model: "claude-test",
+		Messages: []Message{
+			{Role: "system", Content: "Be brief."},
+			{Role: "user", Content: "Summarize this."},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	if recorded.Path != "/v1/messages" {
+		t.Errorf("path = %q, want /v1/messages", recorded.Path)
+	}
+	if got := recorded.Header.Get("x-api-key"); got != "secret" {
+		t.Errorf("x-api-key = %q, want secret", got)
+	}
+	if got := recorded.Header.Get("anthropic-version"); got != anthropicVersion {
+		t.Errorf("anthropic-version = %q, want %s", got, anthropicVersion)
+	}
+	if recorded.Body["system"] != "Be brief." {
+		t.Errorf("system = %v, want the system message lifted out", recorded.Body["system"])
+	}
+	if messages, _ := recorded.Body["messages"].([]any); len(messages) != 1 {
+		t.Errorf("messages = %v, want only the user message", recorded.Body["messages"])
+	}
+	if recorded.Body["max_tokens"] != float64(anthropicDefaultMaxTokens) {
+		t.Errorf("max_tokens = %v, want the default %d", recorded.Body["max_tokens"], anthropicDefaultMaxTokens)
+	}
+
+	if completion.Text != "First part. Second part." || completion.Model != "claude-test-20250101" {
+		t.Errorf("completion = %+v", completion)
+	}
+	if completion.Usage.PromptTokens != 200 || completion.Usage.CompletionTokens != 50 {
+		t.Errorf("usage = %+v, want 200 input and 50 output tokens", completion.Usage)
+	}
+}
+
+func TestAnthropicCompleteErrorBody(t *testing.T) {
+	server, _ := newStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"error":{"type":"overloaded_error","message":"Overloaded"}}`)
+	})
+
+	provider := NewAnthropic(Config{BaseURL: server.URL, APIKey: "secret"})
+	_, err := provider.Complete(context.Background(), Request{Model: "claude-test", MaxTokens: 100})
+	if err == nil || !strings.Contains(err.Error(), "Overloaded") {
+		t.Fatalf("err = %v, want the upstream error message", err)
+	}
+}
+
+func TestAnthropicStream(t *testing.T) {
+	server, recorded := newStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w,
+			"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"model\":\"claude-test-20250101\",\"usage\":{\"input_tokens\":25}}}",
+			"event: content_block_start\ndata: {\"type\":\"content_block_start\"}",
+			"event: ping\ndata: {\"type\":\"ping\"}",
+			"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"Hello\"}}",
+			"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\" there\"}}",
+			"event: message_delta\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":7}}",
+			"event: message_stop\ndata: {\"type\":\"message_stop\"}",
+		)
+	})
+
+	provider := NewAnthropic(Config{BaseURL: server.URL, APIKey: "secret"})
+	var deltas []string
+	completion, err := provider.Stream(context.Background(), Request{Model: "claude-test", MaxTokens: 512}, collectDeltas(&deltas))
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+
+	if recorded.Body["stream"] != true || recorded.Body["max_tokens"] != 512.0 {
+		t.Errorf("request = %v, want stream and the requested max_tokens", recorded.Body)
+	}
+	if strings.Join(deltas, "|") != "Hello| there" {
+		t.Errorf("deltas = %q", deltas)
+	}
+	if completion.Text != "Hello there" || completion.Model != "claude-test-20250101" {
+		t.Errorf("completion = %+v", completion)
+	}
+	if completion.Usage.PromptTokens != 25 || completion.Usage.CompletionTokens != 7 {
+		t.Errorf("usage = %+v, want 25 input and 7 output tokens", completion.Usage)
+	}
+}
+
+func TestAnthropicStreamErrorEvent(t *testing.T) {
+	server, _ := newStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}")
+	})
+
+	provider := NewAnthropic(Config{BaseURL: server.URL, APIKey: "secret"})
+	_, err := provider.Stream(context.Background(), Request{Model: "claude-test"}, func(string) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "Overloaded") {
+		t.Fatalf("err = %v, want the stream error", err)
+	}
+}
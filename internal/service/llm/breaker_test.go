@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errOutage = errors.New("outage")
+
+func TestBreakerOpensAtThreshold(t *testing.T) {
+	b := NewBreaker(BreakerSettings{FailureThreshold: 3, Cooldown: time.Hour})
+
+	for i := 0; i < 2; i++ {
+		if !b.Allow() {
+			t.Fatalf("closed breaker refused call %d", i+1)
+		}
+		b.Failure(errOutage)
+	}
+	if status := b.Status(); status.State != BreakerClosed || status.Failures != 2 {
+		t.Fatalf("status = %+v, want closed with 2 failures", status)
+	}
+
+	b.Failure(errOutage)
+	status := b.Status()
+	if status.State != BreakerOpen || status.OpenedAt == nil || status.LastError != "outage" {
+		t.Fatalf("status = %+v, want open with the last error", status)
+	}
+	if b.Allow() {
+		t.Errorf("open breaker allowed a call during its cooldown")
+	}
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	b := NewBreaker(BreakerSettings{FailureThreshold: 2, Cooldown: time.Hour})
+
+	b.Failure(errOutage)
+	b.Success()
+	b.Failure(errOutage)
+	if status := b.Status(); status.State != BreakerClosed || status.Failures != 1 {
+		t.Errorf("status = %+v, want closed with the count restarted", status)
+	}
+}
+
+func TestBreakerHalfOpenAllowsOneProbe(t *testing.T) {
+	b := NewBreaker(BreakerSettings{FailureThreshold: 1, Cooldown: 10 * time.Millisecond})
+	b.Failure(errOutage)
+	time.Sleep(20 * time.Millisecond)
+
+	if state := b.Status().State; state != BreakerHalfOpen {
+		t.Fatalf("state = %s after the cooldown, want %s", state, BreakerHalfOpen)
+	}
+	if !b.Allow() {
+		t.Fatalf("half-open breaker refused the probe")
+	}
+	if b.Allow() {
+		t.Fatalf("half-open breaker allowed a second call while probing")
+	}
+
+	b.Success()
+	if status := b.Status(); status.State != BreakerClosed || status.Failures != 0 || status.OpenedAt != nil {
+		t.Errorf("status = %+v, want closed after a successful probe", status)
+	}
+}
+
+func TestBreakerFailedProbeReopens(t *testing.T) {
+	b := NewBreaker(BreakerSettings{FailureThreshold: 5, Cooldown: 10 * time.Millisecond})
+	for range 5 {
+		b.Failure(errOutage)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if !b.Allow() {
+		t.Fatalf("half-open breaker refused the probe")
+	}
+	b.Failure(errors.New("still down"))
+	status := b.Status()
+	if status.State != BreakerOpen || status.LastError != "still down" {
+		t.Errorf("status = %+v, want open again after one failed probe", status)
+	}
+	if b.Allow() {
+		t.Errorf("reopened breaker allowed a call before a new cooldown")
+	}
+}
+
+func TestBreakerReleaseFreesProbe(t *testing.T) {
+	b := NewBreaker(BreakerSettings{FailureThreshold: 1, Cooldown: 10 * time.Millisecond})
+	b.Failure(errOutage)
+	time.Sleep(20 * time.Millisecond)
+
+	if !b.Allow() {
+		t.Fatalf("half-open breaker refused the probe")
+	}
+	b.Release()
+	if !b.Allow() {
+		t.Errorf("a released probe should let the next call probe")
+	}
+}
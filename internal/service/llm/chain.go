@@ -0,0 +1,145 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+var ErrNoHealthyTarget = errors.New("no healthy LLM target available")
+
+type Target struct {
+	Provider Provider
+	Model    string
+}
+
+type TargetStatus struct {
+	Provider string
+	Model    string
+	BreakerStatus
+}
+
+type Chain struct {
+	targets  []Target
+	breakers []*Breaker
+	pricing  Pricing
+}
+
+func NewChain(targets []Target, settings BreakerSettings, pricing Pricing) *Chain {
+	breakers := make([]*Breaker, len(targets))
+	for i := range targets {
+		breakers[i] = NewBreaker(settings)
+	}
+	return &Chain{targets: targets, breakers: breakers, pricing: pricing}
+}
+
+func (c *Chain) PreferredModel() string {
+	for i, target := range c.targets {
+		if c.breakers[i].Status().State != BreakerOpen {
+			return target.Model
+		}
+	}
+	return c.targets[0].Model
+}
+
+func (c *Chain) Models() []string {
+	models := make([]string, len(c.targets))
+	for i, target := range c.targets {
+		models[i] = target.Model
+	}
+	return models
+}
+
+func (c *Chain) Complete(ctx context.Context, messages []Message) (*Completion, error) {
+	return c.run(ctx, func(target Target) (*Completion, bool, error) {
+		completion, err := target.Provider.Complete(ctx, Request{Model: target.Model, Messages: messages})
+		return completion, true, err
+	})
+}
+
+func (c *Chain) Stream(ctx context.Context, messages []Message, onDelta func(string) error) (*Completion, error) {
+	return c.run(ctx, func(target Target) (*Completion, bool, error) {
+		streamed := false
+		completion, err := target.Provider.Stream(ctx, Request{Model: target.Model, Messages: messages}, func(delta string) error {
+			streamed = true
+			return onDelta(delta)
+		})
+		return completion, !streamed, err
+	})
+}
+
+func (c *Chain) Status() []TargetStatus {
+	statuses := make([]TargetStatus, len(c.targets))
+	for i, target := range c.targets {
+		statuses[i] = TargetStatus{
+			Provider:      target.Provider.Name(),
+			Model:         target.Model,
+			BreakerStatus: c.breakers[i].Status(),
+		}
+	}
+	return statuses
+}
+
+func (c *Chain) run(ctx context.Context, call func(target Target) (*Completion, bool, error)) (*Completion, error) {
+	var failures []string
+	var lastErr error
+	for i, target := range c.targets {
+		name := target.Provider.Name() + "/" + target.Model
+		breaker := c.breakers[i]
+		if !breaker.Allow() {
+			failures = append(failures, name+": circuit open")
+			continue
+		}
+
+		completion, canFallBack, err := call(target)
+		if err == nil {
+			breaker.Success()
+			completion.Provider = target.Provider.Name()
+			completion.Model = target.Model
+			if completion.Usage.Cost == nil {
+				completion.Usage.Cost = c.pricing.Cost(target.Model, completion.Usage)
+			}
+			return completion, nil
+		}
+
+		if ctx.Err() != nil {
+			breaker.Release()
+			return nil, err
+		}
+
+		if countsAsOutage(err) {
+			breaker.Failure(err)
+		} else {
+			breaker.Release()
+		}
+		log.Printf("LLM target %s failed: %v", name, err)
+		if !canFallBack {
+			return nil, err
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoHealthyTarget, strings.Join(failures, "; "))
+	}
+	if len(failures) == 1 {
+		return nil, lastErr
+	}
+	return nil, &ChainError{Failures: failures, Last: lastErr}
+}
+
+type ChainError struct {
+	Failures []string
+	Last     error
+}
+
+func (e *ChainError) Error() string {
+	return "all LLM targets failed: " + strings.Join(e.Failures, "; ")
+}
+
+func (e *ChainError) Unwrap() error {
+	return e.Last
+}
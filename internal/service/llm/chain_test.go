@@ -0,0 +1,162 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// stubProvider answers every call with err, or with text when err is nil. When
+// deltas is set, Stream sends them before failing with err.
+type stubProvider struct {
+	name   string
+	text   string
+	usage  Usage
+	err    error
+	deltas []string
+	calls  int
+}
+
+func (p *stubProvider) Name() string {
+	return p.name
+}
+
+func (p *stubProvider) Complete(ctx context.Context, req Request) (*Completion, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &Completion{Text: p.text, Model: "ignored", Usage: p.usage}, nil
+}
+
+func (p *stubProvider) Stream(ctx context.Context, req Request, onDelta func(string) error) (*Completion, error) {
+	p.calls++
+	for _, delta := range p.deltas {
+		if err := onDelta(delta); err != nil {
+			return nil, err
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &Completion{Text: p.text, Usage: p.usage}, nil
+}
+
+func outage() error {
+	return &APIError{StatusCode: http.StatusServiceUnavailable, kind: ErrUpstreamUnavailable}
+}
+
+func TestChainFallsBackOnOutage(t *testing.T) {
+	primary := &stubProvider{name: "primary", err: outage()}
+	secondary := &stubProvider{name: "secondary", text: "From the fallback.", usage: Usage{PromptTokens: 1000, CompletionTokens: 500}}
+	chain := NewChain(
+		[]Target{{Provider: primary, Model: "big"}, {Provider: secondary, Model: "small"}},
+		BreakerSettings{FailureThreshold: 1, Cooldown: time.Hour},
+		Pricing{"small": {Prompt: 1, Completion: 2}},
+	)
+
+	completion, err := chain.Complete(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+	if completion.Provider != "secondary" || completion.Model != "small" || completion.Text != "From the fallback." {
+		t.Errorf("completion = %+v, want the secondary target's answer", completion)
+	}
+	if completion.Usage.Cost == nil || *completion.Usage.Cost != 0.002 {
+		t.Errorf("cost = %v, want 0.002 from the pricing table", completion.Usage.Cost)
+	}
+
+	if chain.PreferredModel() != "small" {
+		t.Errorf("PreferredModel = %q, want the fallback while the primary's breaker is open", chain.PreferredModel())
+	}
+	if _, err := chain.Complete(context.Background(), nil); err != nil {
+		t.Fatalf("second Complete returned error: %v", err)
+	}
+	if primary.calls != 1 {
+		t.Errorf("primary calls = %d, want the open breaker to skip it", primary.calls)
+	}
+}
+
+func TestChainDoesNotTripOnRejectedRequests(t *testing.T) {
+	rejected := &APIError{StatusCode: http.StatusBadRequest, kind: ErrUpstreamRejected}
+	primary := &stubProvider{name: "primary", err: rejected}
+	secondary := &stubProvider{name: "secondary", text: "ok"}
+	chain := NewChain(
+		[]Target{{Provider: primary, Model: "big"}, {Provider: secondary, Model: "small"}},
+		BreakerSettings{FailureThreshold: 1, Cooldown: time.Hour},
+		nil,
+	)
+
+	if _, err := chain.Complete(context.Background(), nil); err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+	if state := chain.Status()[0].State; state != BreakerClosed {
+		t.Errorf("primary breaker = %s, want closed after a rejected request", state)
+	}
+}
+
+func TestChainKeepsProviderCost(t *testing.T) {
+	cost := 0.5
+	primary := &stubProvider{name: "primary", text: "ok", usage: Usage{PromptTokens: 10, Cost: &cost}}
+	chain := NewChain([]Target{{Provider: primary, Model: "big"}}, BreakerSettings{}, Pricing{"big": {Prompt: 100}})
+
+	completion, err := chain.Complete(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+	if completion.Usage.Cost == nil || *completion.Usage.Cost != 0.5 {
+		t.Errorf("cost = %v, want the provider's own figure", completion.Usage.Cost)
+	}
+}
+
+func TestChainReportsEveryFailure(t *testing.T) {
+	primary := &stubProvider{name: "primary", err: outage()}
+	secondary := &stubProvider{name: "secondary", err: &APIError{StatusCode: http.StatusTooManyRequests, kind: ErrRateLimited}}
+	chain := NewChain(
+		[]Target{{Provider: primary, Model: "big"}, {Provider: secondary, Model: "small"}},
+		BreakerSettings{FailureThreshold: 1, Cooldown: time.Hour},
+		nil,
+	)
+
+	_, err := chain.Complete(context.Background(), nil)
+	var chainErr *ChainError
+	if !errors.As(err, &chainErr) || len(chainErr.Failures) != 2 {
+		t.Fatalf("err = %v, want a ChainError with both failures", err)
+	}
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("err = %v, want it to unwrap to the last failure", err)
+	}
+
+	_, err = chain.Complete(context.Background(), nil)
+	if !errors.Is(err, ErrNoHealthyTarget) {
+		t.Errorf("err = %v, want ErrNoHealthyTarget once every breaker is open", err)
+	}
+}
+
+func TestChainStreamFallsBackOnlyBeforeOutput(t *testing.T) {
+	primary := &stubProvider{name: "primary", err: outage()}
+	secondary := &stubProvider{name: "secondary", text: "ab", deltas: []string{"a", "b"}}
+	chain := NewChain(
+		[]Target{{Provider: primary, Model: "big"}, {Provider: secondary, Model: "small"}},
+		BreakerSettings{FailureThreshold: 5, Cooldown: time.Hour},
+		nil,
+	)
+
+	var deltas []string
+	completion, err := chain.Stream(context.Background(), nil, collectDeltas(&deltas))
+	if err != nil || completion.Model != "small" || len(deltas) != 2 {
+		t.Fatalf("completion = %+v, deltas = %q, err = %v, want the fallback's stream", completion, deltas, err)
+	}
+
+	primary.deltas = []string{"partial"}
+	deltas = nil
+	_, err = chain.Stream(context.Background(), nil, collectDeltas(&deltas))
+	if !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Fatalf("err = %v, want the primary's failure once output was sent", err)
+	}
+	if secondary.calls != 1 {
+		t.Errorf("secondary calls = %d, want no fallback after streaming began", secondary.calls)
+	}
+}
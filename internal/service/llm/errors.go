@@ -0,0 +1,101 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrRateLimited         = errors.New("upstream rate limited")
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	ErrUpstreamTimeout     = errors.New("upstream timed out")
+	ErrUpstreamAuth        = errors.New("upstream authentication failed")
+	ErrContextLength       = errors.New("content exceeds the model context length")
+	ErrUpstreamRejected    = errors.New("upstream rejected the request")
+)
+
+type APIError struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration
+	kind       error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	return e.kind == target
+}
+
+func (e *APIError) Retryable() bool {
+	return e.kind == ErrRateLimited || e.kind == ErrUpstreamUnavailable || e.kind == ErrUpstreamTimeout
+}
+
+type NetworkError struct {
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("failed to make request: %v", e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+func (e *NetworkError) Is(target error) bool {
+	var timeout interface{ Timeout() bool }
+	if errors.As(e.Err, &timeout) && timeout.Timeout() {
+		return target == ErrUpstreamTimeout
+	}
+	return target == ErrUpstreamUnavailable
+}
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	message := string(body)
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    message,
+		RetryAfter: retryAfter(resp.Header, time.Now()),
+		kind:       classifyStatus(resp.StatusCode, message),
+	}
+}
+
+func classifyStatus(status int, message string) error {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return ErrUpstreamTimeout
+	case status >= 500:
+		return ErrUpstreamUnavailable
+	case status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusPaymentRequired:
+		return ErrUpstreamAuth
+	case status == http.StatusRequestEntityTooLarge || isContextLengthMessage(message):
+		return ErrContextLength
+	}
+	return ErrUpstreamRejected
+}
+
+func isContextLengthMessage(message string) bool {
+	message = strings.ToLower(message)
+	for _, marker := range []string{"context length", "context_length", "context window", "maximum context", "prompt is too long", "too many tokens"} {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func countsAsOutage(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable() || errors.Is(apiErr, ErrUpstreamAuth)
+	}
+	return true
+}
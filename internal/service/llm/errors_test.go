@@ -0,0 +1,132 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHTTPErrorClassification(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		header     map[string]string
+		body       string
+		kind       error
+		retryable  bool
+		retryAfter time.Duration
+	}{
+		{"rate limited", http.StatusTooManyRequests, map[string]string{"Retry-After": "7"}, "slow down", ErrRateLimited, true, 7 * time.Second},
+		{"rate limit reset", http.StatusTooManyRequests, map[string]string{"X-RateLimit-Reset": "3"}, "", ErrRateLimited, true, 3 * time.Second},
+		{"server error", http.StatusInternalServerError, nil, "boom", ErrUpstreamUnavailable, true, 0},
+		{"bad gateway", http.StatusBadGateway, nil, "", ErrUpstreamUnavailable, true, 0},
+		{"gateway timeout", http.StatusGatewayTimeout, nil, "", ErrUpstreamTimeout, true, 0},
+		{"unauthorized", http.StatusUnauthorized, nil, "invalid key", ErrUpstreamAuth, false, 0},
+		{"out of credits", http.StatusPaymentRequired, nil, "", ErrUpstreamAuth, false, 0},
+		{"context length", http.StatusBadRequest, nil, `{"error":{"message":"This model's maximum context length is 8192 tokens"}}`, ErrContextLength, false, 0},
+		{"payload too large", http.StatusRequestEntityTooLarge, nil, "", ErrContextLength, false, 0},
+		{"rejected", http.StatusBadRequest, nil, "unknown parameter", ErrUpstreamRejected, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+				for key, value := range tt.header {
+					w.Header().Set(key, value)
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			})
+
+			provider := NewOpenAI(ProviderOpenAI, Config{BaseURL: server.URL})
+			_, err := provider.Complete(context.Background(), Request{Model: "gpt-test"})
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want an *APIError", err)
+			}
+			if !errors.Is(err, tt.kind) {
+				t.Errorf("err = %v, want it to match %v", err, tt.kind)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != tt.body {
+				t.Errorf("status and message = %d %q, want %d %q", apiErr.StatusCode, apiErr.Message, tt.status, tt.body)
+			}
+			if apiErr.Retryable() != tt.retryable {
+				t.Errorf("Retryable() = %v, want %v", apiErr.Retryable(), tt.retryable)
+			}
+			if apiErr.RetryAfter != tt.retryAfter {
+				t.Errorf("RetryAfter = %s, want %s", apiErr.RetryAfter, tt.retryAfter)
+			}
+		})
+	}
+}
+
+func TestNetworkErrorClassification(t *testing.T) {
+	server, _ := newStandIn(t, func(w http.ResponseWriter, r *http.Request) {})
+	server.Close()
+
+	provider := NewOpenAI(ProviderOpenAI, Config{BaseURL: server.URL})
+	_, err := provider.Complete(context.Background(), Request{Model: "gpt-test"})
+
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) || !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Fatalf("err = %v, want an unavailable *NetworkError", err)
+	}
+	if !countsAsOutage(err) {
+		t.Errorf("a refused connection should count as an outage")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   time.Duration
+	}{
+		{"none", nil, 0},
+		{"seconds", map[string]string{"Retry-After": "30"}, 30 * time.Second},
+		{"http date", map[string]string{"Retry-After": now.Add(90 * time.Second).Format(http.TimeFormat)}, 90 * time.Second},
+		{"http date in the past", map[string]string{"Retry-After": now.Add(-time.Minute).Format(http.TimeFormat)}, 0},
+		{"reset seconds", map[string]string{"X-RateLimit-Reset": "12"}, 12 * time.Second},
+		{"reset unix", map[string]string{"X-RateLimit-Reset": "1767323105"}, 60 * time.Second},
+		{"reset unix millis", map[string]string{"X-RateLimit-Reset": "1767323050000"}, 5 * time.Second},
+		{"retry after wins", map[string]string{"Retry-After": "4", "X-RateLimit-Reset": "12"}, 4 * time.Second},
+		{"garbage", map[string]string{"Retry-After": "soon"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range tt.header {
+				header.Set(key, value)
+			}
+			if got := retryAfter(header, now); got != tt.want {
+				t.Errorf("retryAfter = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountsAsOutage(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusTooManyRequests, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusUnauthorized, true},
+		{http.StatusBadRequest, false},
+		{http.StatusRequestEntityTooLarge, false},
+	}
+
+	for _, tt := range tests {
+		err := &APIError{StatusCode: tt.status, kind: classifyStatus(tt.status, "")}
+		if got := countsAsOutage(err); got != tt.want {
+			t.Errorf("countsAsOutage(%d) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
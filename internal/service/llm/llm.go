@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	ProviderOpenRouter = "openrouter"
+	ProviderOpenAI     = "openai"
+	ProviderAnthropic  = "anthropic"
+	ProviderOllama     = "ollama"
+)
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type Request struct {
+	Model     string
+	Messages  []Message
+	MaxTokens int
+}
+
+type Completion struct {
+	Text     string
+	Provider string
+	Model    string
+	Usage    Usage
+}
+
+type Provider interface {
+	Name() string
+	Complete(ctx context.Context, req Request) (*Completion, error)
+	Stream(ctx context.Context, req Request, onDelta func(string) error) (*Completion, error)
+}
+
+type Config struct {
+	Provider string
+	BaseURL  string
+	APIKey   string
+	Timeout  time.Duration
+	Retry    RetryPolicy
+}
+
+func NewProvider(config Config) (Provider, error) {
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	switch config.Provider {
+	case ProviderOpenRouter, "":
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("an API key is required for the %s provider", ProviderOpenRouter)
+		}
+		return NewOpenAI(ProviderOpenRouter, withDefaultBaseURL(config, "https://openrouter.ai/api/v1")), nil
+	case ProviderOpenAI:
+		return NewOpenAI(ProviderOpenAI, withDefaultBaseURL(config, "https://api.openai.com/v1")), nil
+	case ProviderAnthropic:
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("an API key is required for the %s provider", ProviderAnthropic)
+		}
+		return NewAnthropic(withDefaultBaseURL(config, "https://api.anthropic.com")), nil
+	case ProviderOllama:
+		return NewOllama(withDefaultBaseURL(config, "http://localhost:11434")), nil
+	}
+	return nil, fmt.Errorf("unknown LLM provider %q", config.Provider)
+}
+
+func withDefaultBaseURL(config Config, baseURL string) Config {
+	if config.BaseURL == "" {
+		config.BaseURL = baseURL
+	}
+	return config
+}
+
+type httpClients struct {
+	complete *http.Client
+	stream   *http.Client
+	retry    RetryPolicy
+}
+
+func newHTTPClients(config Config) httpClients {
+	return httpClients{
+		complete: &http.Client{Timeout: config.Timeout},
+		stream:   &http.Client{},
+		retry:    config.Retry,
+	}
+}
@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type Ollama struct {
+	baseURL string
+	apiKey  string
+	clients httpClients
+}
+
+type ollamaRequest struct {
+	Model    string         `json:"model"`
+	Messages []Message      `json:"messages"`
+	Stream   bool           `json:"stream"`
+	Options  *ollamaOptions `json:"options,omitempty"`
+}
+
+type ollamaOptions struct {
+	NumPredict int `json:"num_predict,omitempty"`
+}
+
+type ollamaResponse struct {
+	Model           string  `json:"model"`
+	Message         Message `json:"message"`
+	Done            bool    `json:"done"`
+	PromptEvalCount int     `json:"prompt_eval_count,omitempty"`
+	EvalCount       int     `json:"eval_count,omitempty"`
+	Error           string  `json:"error,omitempty"`
+}
+
+func NewOllama(config Config) *Ollama {
+	return &Ollama{
+		baseURL: strings.TrimSuffix(config.BaseURL, "/"),
+		apiKey:  config.APIKey,
+		clients: newHTTPClients(config),
+	}
+}
+
+func (p *Ollama) Name() string {
+	return ProviderOllama
+}
+
+func (p *Ollama) Complete(ctx context.Context, req Request) (*Completion, error) {
+	resp, err := p.clients.post(ctx, false, p.baseURL+"/api/chat", p.headers(), p.request(req, false))
+	if err != nil {
+		return nil, err
+	}
+
+	var body ollamaResponse
+	if err := decodeJSON(resp, &body); err != nil {
+		return nil, err
+	}
+
+	if body.Error != "" {
+		return nil, fmt.Errorf("ollama API error: %s", body.Error)
+	}
+
+	if body.Message.Content == "" {
+		return nil, fmt.Errorf("no response received from %s", ProviderOllama)
+	}
+
+	return &Completion{
+		Text:  body.Message.Content,
+		Model: modelOrDefault(body.Model, req.Model),
+		Usage: body.usage(),
+	}, nil
+}
+
+func (p *Ollama) Stream(ctx context.Context, req Request, onDelta func(string) error) (*Completion, error) {
+	resp, err := p.clients.post(ctx, true, p.baseURL+"/api/chat", p.headers(), p.request(req, true))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var text strings.Builder
+	var usage Usage
+	model := req.Model
+	err = readLines(resp.Body, func(line string) (bool, error) {
+		if strings.TrimSpace(line) == "" {
+			return false, nil
+		}
+
+		var chunk ollamaResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return false, fmt.Errorf("failed to unmarshal stream chunk: %w", err)
+		}
+
+		if chunk.Error != "" {
+			return false, fmt.Errorf("ollama API error: %s", chunk.Error)
+		}
+
+		model = modelOrDefault(chunk.Model, model)
+		if chunk.Message.Content != "" {
+			text.WriteString(chunk.Message.Content)
+			if err := onDelta(chunk.Message.Content); err != nil {
+				return false, err
+			}
+		}
+		if chunk.Done {
+			usage = chunk.usage()
+		}
+		return chunk.Done, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("no response received from %s", ProviderOllama)
+	}
+
+	return &Completion{Text: text.String(), Model: model, Usage: usage}, nil
+}
+
+func (p *Ollama) request(req Request, stream bool) ollamaRequest {
+	body := ollamaRequest{
+		Model:    req.Model,
+		Messages: req.Messages,
+		Stream:   stream,
+	}
+	if req.MaxTokens > 0 {
+		body.Options = &ollamaOptions{NumPredict: req.MaxTokens}
+	}
+	return body
+}
+
+func (r ollamaResponse) usage() Usage {
+	return Usage{PromptTokens: r.PromptEvalCount, CompletionTokens: r.EvalCount}
+}
+
+func (p *Ollama) headers() map[string]string {
+	headers := map[string]string{}
+	if p.apiKey != "" {
+		headers["Authorization"] = "Bearer " + p.apiKey
+	}
+	return headers
+}
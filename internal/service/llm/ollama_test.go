@@ -0,0 +1,95 @@
+package llm
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOllamaComplete(t *testing.T) {
+	server, recorded := newStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"model":"llama-test","message":{"role":"assistant","content":"A summary."},
+			"done":true,"prompt_eval_count":80,"eval_count":15}`)
+	})
+
+	provider := NewOllama(Config{BaseURL: server.URL})
+	completion, err := provider.Complete(context.Background(), Request{
+		Model:     "llama-test",
+		Messages:  []Message{{Role: "user", Content: "Summarize this."}},
+		MaxTokens: 300,
+	})
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	if recorded.Path != "/api/chat" {
+		t.Errorf("path = %q, want /api/chat", recorded.Path)
+	}
+	if recorded.Body["stream"] != false {
+		t.Errorf("stream = %v, want an explicit false", recorded.Body["stream"])
+	}
+	if options, _ := recorded.Body["options"].(map[string]any); options["num_predict"] != 300.0 {
+		t.Errorf("options = %v, want num_predict 300", recorded.Body["options"])
+	}
+	if got := recorded.Header.Get("Authorization"); got != "" {
+		t.Errorf("Authorization = %q, want none without an API key", got)
+	}
+
+	if completion.Text != "A summary." || completion.Model != "llama-test" {
+		t.Errorf("completion = %+v", completion)
+	}
+	if completion.Usage.PromptTokens != 80 || completion.Usage.CompletionTokens != 15 {
+		t.Errorf("usage = %+v, want 80 prompt and 15 completion tokens", completion.Usage)
+	}
+}
+
+func TestOllamaCompleteErrorBody(t *testing.T) {
+	server, _ := newStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"error":"model not loaded"}`)
+	})
+
+	provider := NewOllama(Config{BaseURL: server.URL, APIKey: "secret"})
+	_, err := provider.Complete(context.Background(), Request{Model: "llama-test"})
+	if err == nil || !strings.Contains(err.Error(), "model not loaded") {
+		t.Fatalf("err = %v, want the upstream error message", err)
+	}
+}
+
+func TestOllamaStream(t *testing.T) {
+	server, recorded := newStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		io.WriteString(w, `{"model":"llama-test","message":{"content":"Hello"},"done":false}`+"\n\n")
+		io.WriteString(w, `{"model":"llama-test","message":{"content":" world"},"done":false}`+"\n")
+		io.WriteString(w, `{"model":"llama-test","message":{"content":""},"done":true,"prompt_eval_count":9,"eval_count":2}`+"\n")
+		io.WriteString(w, `{"model":"llama-test","message":{"content":"ignored"},"done":false}`+"\n")
+	})
+
+	provider := NewOllama(Config{BaseURL: server.URL, APIKey: "secret"})
+	var deltas []string
+	completion, err := provider.Stream(context.Background(), Request{Model: "llama-test"}, collectDeltas(&deltas))
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+
+	if recorded.Body["stream"] != true {
+		t.Errorf("stream = %v, want true", recorded.Body["stream"])
+	}
+	if _, ok := recorded.Body["options"]; ok {
+		t.Errorf("options should be omitted without MaxTokens: %v", recorded.Body)
+	}
+	if got := recorded.Header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q, want Bearer secret", got)
+	}
+
+	if strings.Join(deltas, "|") != "Hello| world" {
+		t.Errorf("deltas = %q, want nothing after done", deltas)
+	}
+	if completion.Text != "Hello world" {
+		t.Errorf("text = %q", completion.Text)
+	}
+	if completion.Usage.PromptTokens != 9 || completion.Usage.CompletionTokens != 2 {
+		t.Errorf("usage = %+v, want the final chunk's counts", completion.Usage)
+	}
+}
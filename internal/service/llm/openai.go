@@ -0,0 +1,183 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type OpenAI struct {
+	name    string
+	baseURL string
+	apiKey  string
+	clients httpClients
+}
+
+type openAIRequest struct {
+	Model         string               `json:"model"`
+	Messages      []Message            `json:"messages"`
+	MaxTokens     int                  `json:"max_tokens,omitempty"`
+	Stream        bool                 `json:"stream,omitempty"`
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+	Usage         *openRouterUsage     `json:"usage,omitempty"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// openRouterUsage asks OpenRouter to report the cost of the call alongside token counts.
+type openRouterUsage struct {
+	Include bool `json:"include"`
+}
+
+type openAIResponse struct {
+	Model   string         `json:"model"`
+	Choices []openAIChoice `json:"choices"`
+	Usage   *openAIUsage   `json:"usage,omitempty"`
+	Error   *openAIError   `json:"error,omitempty"`
+}
+
+type openAIUsage struct {
+	PromptTokens     int      `json:"prompt_tokens"`
+	CompletionTokens int      `json:"completion_tokens"`
+	Cost             *float64 `json:"cost,omitempty"`
+}
+
+type openAIChoice struct {
+	Message Message `json:"message"`
+	Delta   Message `json:"delta"`
+}
+
+type openAIError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+func NewOpenAI(name string, config Config) *OpenAI {
+	return &OpenAI{
+		name:    name,
+		baseURL: strings.TrimSuffix(config.BaseURL, "/"),
+		apiKey:  config.APIKey,
+		clients: newHTTPClients(config),
+	}
+}
+
+func (p *OpenAI) Name() string {
+	return p.name
+}
+
+func (p *OpenAI) Complete(ctx context.Context, req Request) (*Completion, error) {
+	resp, err := p.clients.post(ctx, false, p.baseURL+"/chat/completions", p.headers(), p.request(req, false))
+	if err != nil {
+		return nil, err
+	}
+
+	var body openAIResponse
+	if err := decodeJSON(resp, &body); err != nil {
+		return nil, err
+	}
+
+	if body.Error != nil {
+		return nil, fmt.Errorf("%s API error: %s", p.name, body.Error.Message)
+	}
+
+	if len(body.Choices) == 0 {
+		return nil, fmt.Errorf("no response received from %s", p.name)
+	}
+
+	return &Completion{
+		Text:  body.Choices[0].Message.Content,
+		Model: modelOrDefault(body.Model, req.Model),
+		Usage: body.Usage.toUsage(),
+	}, nil
+}
+
+func (p *OpenAI) Stream(ctx context.Context, req Request, onDelta func(string) error) (*Completion, error) {
+	headers := p.headers()
+	headers["Accept"] = "text/event-stream"
+
+	resp, err := p.clients.post(ctx, true, p.baseURL+"/chat/completions", headers, p.request(req, true))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var text strings.Builder
+	var usage Usage
+	model := req.Model
+	err = readServerSentEvents(resp.Body, func(_, data string) (bool, error) {
+		if data == "[DONE]" {
+			return true, nil
+		}
+
+		var chunk openAIResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return false, fmt.Errorf("failed to unmarshal stream chunk: %w", err)
+		}
+
+		if chunk.Error != nil {
+			return false, fmt.Errorf("%s API error: %s", p.name, chunk.Error.Message)
+		}
+
+		model = modelOrDefault(chunk.Model, model)
+		if chunk.Usage != nil {
+			usage = chunk.Usage.toUsage()
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			return false, nil
+		}
+
+		delta := chunk.Choices[0].Delta.Content
+		text.WriteString(delta)
+		return false, onDelta(delta)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("no response received from %s", p.name)
+	}
+
+	return &Completion{Text: text.String(), Model: model, Usage: usage}, nil
+}
+
+func (p *OpenAI) request(req Request, stream bool) openAIRequest {
+	body := openAIRequest{
+		Model:     req.Model,
+		Messages:  req.Messages,
+		MaxTokens: req.MaxTokens,
+		Stream:    stream,
+	}
+	if stream {
+		body.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
+	if p.name == ProviderOpenRouter {
+		body.Usage = &openRouterUsage{Include: true}
+	}
+	return body
+}
+
+func (u *openAIUsage) toUsage() Usage {
+	if u == nil {
+		return Usage{}
+	}
+	return Usage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens, Cost: u.Cost}
+}
+
+func (p *OpenAI) headers() map[string]string {
+	headers := map[string]string{}
+	if p.apiKey != "" {
+		headers["Authorization"] = "Bearer " + p.apiKey
+	}
+	return headers
+}
+
+func modelOrDefault(model, fallback string) string {
+	if model == "" {
+		return fallback
+	}
+	return model
+}
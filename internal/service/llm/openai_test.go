@@ -0,0 +1,195 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// recordedRequest is what a stand-in server saw of the last request it served.
+type recordedRequest struct {
+	Path   string
+	Header http.Header
+	Body   map[string]any
+}
+
+// newStandIn starts a server that records each request and answers with respond.
+func newStandIn(t *testing.T, respond func(w http.ResponseWriter, r *http.Request)) (*httptest.Server, *recordedRequest) {
+	t.Helper()
+	recorded := &recordedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read request body: %v", err)
+		}
+		recorded.Path = r.URL.Path
+		recorded.Header = r.Header.Clone()
+		recorded.Body = nil
+		if err := json.Unmarshal(data, &recorded.Body); err != nil {
+			t.Errorf("request body is not JSON: %v", err)
+		}
+		respond(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, recorded
+}
+
+func writeEvents(w http.ResponseWriter, events ...string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, event := range events {
+		io.WriteString(w, event+"\n\n")
+	}
+}
+
+func collectDeltas(deltas *[]string) func(string) error {
+	return func(delta string) error {
+		*deltas = append(*deltas, delta)
+		return nil
+	}
+}
+
+func TestOpenAIComplete(t *testing.T) {
+	server, recorded := newStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"model":"gpt-test-0613","choices":[{"message":{"role":"assistant","content":"A summary."}}],
+			"usage":{"prompt_tokens":120,"completion_tokens":30,"cost":0.0042}}`)
+	})
+
+	provider := NewOpenAI(ProviderOpenRouter, Config{BaseURL: server.URL + "/", APIKey: "secret"})
+	completion, err := provider.Complete(context.Background(), Request{
+		Model:     "gpt-test",
+		Messages:  []Message{{Role: "user", Content: "Summarize this."}},
+		MaxTokens: 256,
+	})
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	if recorded.Path != "/chat/completions" {
+		t.Errorf("path = %q, want /chat/completions", recorded.Path)
+	}
+	if got := recorded.Header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q, want Bearer secret", got)
+	}
+	if recorded.Body["model"] != "gpt-test" || recorded.Body["max_tokens"] != 256.0 {
+		t.Errorf("model and max_tokens not sent as requested: %v", recorded.Body)
+	}
+	if _, ok := recorded.Body["stream"]; ok {
+		t.Errorf("stream should be omitted for a completion: %v", recorded.Body)
+	}
+	if usage, _ := recorded.Body["usage"].(map[string]any); usage["include"] != true {
+		t.Errorf("OpenRouter requests should ask for usage, got %v", recorded.Body["usage"])
+	}
+	messages, _ := recorded.Body["messages"].([]any)
+	if len(messages) != 1 || messages[0].(map[string]any)["content"] != "Summarize this." {
+		t.Errorf("messages = %v", recorded.Body["messages"])
+	}
+
+	if completion.Text != "A summary." || completion.Model != "gpt-test-0613" {
+		t.Errorf("completion = %+v", completion)
+	}
+	if completion.Usage.PromptTokens != 120 || completion.Usage.CompletionTokens != 30 {
+		t.Errorf("usage = %+v, want 120 prompt and 30 completion tokens", completion.Usage)
+	}
+	if completion.Usage.Cost == nil || *completion.Usage.Cost != 0.0042 {
+		t.Errorf("cost = %v, want 0.0042", completion.Usage.Cost)
+	}
+}
+
+func TestOpenAICompleteWithoutUsage(t *testing.T) {
+	server, recorded := newStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"choices":[{"message":{"role":"assistant","content":"Done."}}]}`)
+	})
+
+	provider := NewOpenAI(ProviderOpenAI, Config{BaseURL: server.URL})
+	completion, err := provider.Complete(context.Background(), Request{Model: "gpt-test"})
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	if got := recorded.Header.Get("Authorization"); got != "" {
+		t.Errorf("Authorization = %q, want none without an API key", got)
+	}
+	if _, ok := recorded.Body["usage"]; ok {
+		t.Errorf("only OpenRouter requests should ask for usage: %v", recorded.Body)
+	}
+	if completion.Model != "gpt-test" {
+		t.Errorf("model = %q, want the requested model when the response has none", completion.Model)
+	}
+	if completion.Usage != (Usage{}) {
+		t.Errorf("usage = %+v, want zero", completion.Usage)
+	}
+}
+
+func TestOpenAICompleteErrorBody(t *testing.T) {
+	server, _ := newStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"error":{"message":"model overloaded","type":"server_error"}}`)
+	})
+
+	provider := NewOpenAI(ProviderOpenAI, Config{BaseURL: server.URL})
+	_, err := provider.Complete(context.Background(), Request{Model: "gpt-test"})
+	if err == nil || !strings.Contains(err.Error(), "model overloaded") {
+		t.Fatalf("err = %v, want the upstream error message", err)
+	}
+}
+
+func TestOpenAIStream(t *testing.T) {
+	server, recorded := newStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w,
+			`data: {"model":"gpt-test-0613","choices":[{"delta":{"role":"assistant"}}]}`,
+			`data: {"choices":[{"delta":{"content":"Hello"}}]}`,
+			`data: {"choices":[{"delta":{"content":", world"}}]}`,
+			`data: {"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":4,"cost":0.001}}`,
+			`data: [DONE]`,
+		)
+	})
+
+	provider := NewOpenAI(ProviderOpenAI, Config{BaseURL: server.URL, APIKey: "secret"})
+	var deltas []string
+	completion, err := provider.Stream(context.Background(), Request{Model: "gpt-test"}, collectDeltas(&deltas))
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+
+	if recorded.Body["stream"] != true {
+		t.Errorf("stream = %v, want true", recorded.Body["stream"])
+	}
+	if options, _ := recorded.Body["stream_options"].(map[string]any); options["include_usage"] != true {
+		t.Errorf("stream_options = %v, want include_usage", recorded.Body["stream_options"])
+	}
+	if got := recorded.Header.Get("Accept"); got != "text/event-stream" {
+		t.Errorf("Accept = %q, want text/event-stream", got)
+	}
+
+	if strings.Join(deltas, "|") != "Hello|, world" {
+		t.Errorf("deltas = %q", deltas)
+	}
+	if completion.Text != "Hello, world" || completion.Model != "gpt-test-0613" {
+		t.Errorf("completion = %+v", completion)
+	}
+	if completion.Usage.PromptTokens != 12 || completion.Usage.CompletionTokens != 4 || completion.Usage.Cost == nil {
+		t.Errorf("usage = %+v, want the final chunk's usage", completion.Usage)
+	}
+}
+
+func TestOpenAIStreamErrorEvent(t *testing.T) {
+	server, _ := newStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w,
+			`data: {"choices":[{"delta":{"content":"Partial"}}]}`,
+			`data: {"error":{"message":"stream interrupted"}}`,
+		)
+	})
+
+	provider := NewOpenAI(ProviderOpenAI, Config{BaseURL: server.URL})
+	var deltas []string
+	_, err := provider.Stream(context.Background(), Request{Model: "gpt-test"}, collectDeltas(&deltas))
+	if err == nil || !strings.Contains(err.Error(), "stream interrupted") {
+		t.Fatalf("err = %v, want the stream error", err)
+	}
+	if len(deltas) != 1 {
+		t.Errorf("deltas = %q, want the one sent before the error", deltas)
+	}
+}
@@ -0,0 +1,152 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingServer answers with the given statuses in turn and then with a completion.
+func failingServer(t *testing.T, header http.Header, statuses ...int) (string, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server, _ := newStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+		call := int(calls.Add(1))
+		if call <= len(statuses) {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(statuses[call-1])
+			io.WriteString(w, "failure")
+			return
+		}
+		io.WriteString(w, `{"choices":[{"message":{"content":"Recovered."}}]}`)
+	})
+	return server.URL, &calls
+}
+
+func TestRetriesTransientFailures(t *testing.T) {
+	url, calls := failingServer(t, nil, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+
+	provider := NewOpenAI(ProviderOpenAI, Config{
+		BaseURL: url,
+		Retry:   RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond},
+	})
+	completion, err := provider.Complete(context.Background(), Request{Model: "gpt-test"})
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+	if completion.Text != "Recovered." {
+		t.Errorf("text = %q", completion.Text)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("calls = %d, want 3", got)
+	}
+}
+
+func TestRetriesStopAtMaxRetries(t *testing.T) {
+	url, calls := failingServer(t, nil, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+
+	provider := NewOpenAI(ProviderOpenAI, Config{
+		BaseURL: url,
+		Retry:   RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond},
+	})
+	_, err := provider.Complete(context.Background(), Request{Model: "gpt-test"})
+	if !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Fatalf("err = %v, want the last upstream failure", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("calls = %d, want the first attempt and 2 retries", got)
+	}
+}
+
+func TestNoRetryForFatalErrors(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized} {
+		url, calls := failingServer(t, nil, status)
+
+		provider := NewOpenAI(ProviderOpenAI, Config{
+			BaseURL: url,
+			Retry:   RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond},
+		})
+		if _, err := provider.Complete(context.Background(), Request{Model: "gpt-test"}); err == nil {
+			t.Fatalf("status %d: expected an error", status)
+		}
+		if got := calls.Load(); got != 1 {
+			t.Errorf("status %d: calls = %d, want no retries", status, got)
+		}
+	}
+}
+
+func TestRetryHonoursRetryAfter(t *testing.T) {
+	url, calls := failingServer(t, http.Header{"Retry-After": {"1"}}, http.StatusTooManyRequests)
+
+	provider := NewOpenAI(ProviderOpenAI, Config{
+		BaseURL: url,
+		Retry:   RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second},
+	})
+	start := time.Now()
+	if _, err := provider.Complete(context.Background(), Request{Model: "gpt-test"}); err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, want at least the 1s Retry-After", elapsed)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
+
+func TestNoRetryWhenRetryAfterExceedsMaxDelay(t *testing.T) {
+	url, calls := failingServer(t, http.Header{"Retry-After": {"120"}}, http.StatusTooManyRequests)
+
+	provider := NewOpenAI(ProviderOpenAI, Config{
+		BaseURL: url,
+		Retry:   RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second},
+	})
+	_, err := provider.Complete(context.Background(), Request{Model: "gpt-test"})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("err = %v, want the rate limit error", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want no retry", got)
+	}
+}
+
+func TestNoRetryPastContextDeadline(t *testing.T) {
+	url, calls := failingServer(t, http.Header{"Retry-After": {"2"}}, http.StatusServiceUnavailable)
+
+	provider := NewOpenAI(ProviderOpenAI, Config{
+		BaseURL: url,
+		Retry:   RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Second},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := provider.Complete(ctx, Request{Model: "gpt-test"})
+	if !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Fatalf("err = %v, want the upstream failure", err)
+	}
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("gave up after %s, want immediately when the wait outlasts the deadline", elapsed)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+}
+
+func TestBackoffStaysWithinBounds(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt := 0; attempt < 10; attempt++ {
+		limit := min(policy.BaseDelay<<attempt, policy.MaxDelay)
+		for range 50 {
+			if delay := policy.backoff(attempt); delay < 0 || delay > limit {
+				t.Fatalf("backoff(%d) = %s, want within [0, %s]", attempt, delay, limit)
+			}
+		}
+	}
+}
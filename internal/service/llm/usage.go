@@ -0,0 +1,76 @@
+package llm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	// Cost is in USD and nil when neither the provider nor the pricing table knows it.
+	Cost *float64
+}
+
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	if other.Cost != nil {
+		total := *other.Cost
+		if u.Cost != nil {
+			total += *u.Cost
+		}
+		u.Cost = &total
+	}
+}
+
+// Price is expressed in USD per million tokens.
+type Price struct {
+	Prompt     float64
+	Completion float64
+}
+
+type Pricing map[string]Price
+
+func (p Pricing) Cost(model string, usage Usage) *float64 {
+	price, ok := p[model]
+	if !ok {
+		return nil
+	}
+	cost := (float64(usage.PromptTokens)*price.Prompt + float64(usage.CompletionTokens)*price.Completion) / 1e6
+	return &cost
+}
+
+// ParsePricing reads "model=prompt:completion" pairs separated by commas.
+func ParsePricing(spec string) (Pricing, error) {
+	pricing := make(Pricing)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		model, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid LLM price %q", entry)
+		}
+
+		promptPrice, completionPrice, ok := strings.Cut(value, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid LLM price %q, expected model=prompt:completion", entry)
+		}
+
+		var price Price
+		var err error
+		if price.Prompt, err = strconv.ParseFloat(promptPrice, 64); err != nil || price.Prompt < 0 {
+			return nil, fmt.Errorf("invalid prompt price in %q", entry)
+		}
+		if price.Completion, err = strconv.ParseFloat(completionPrice, 64); err != nil || price.Completion < 0 {
+			return nil, fmt.Errorf("invalid completion price in %q", entry)
+		}
+
+		pricing[strings.TrimSpace(model)] = price
+	}
+	return pricing, nil
+}
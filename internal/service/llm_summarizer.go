@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"anpurnama/summarizer-backend/internal/repository"
+	"anpurnama/summarizer-backend/internal/service/chunking"
+	"anpurnama/summarizer-backend/internal/service/llm"
+	"anpurnama/summarizer-backend/internal/service/prompt"
+)
+
+type LLMSummarizer struct {
+	styleRepo   repository.StyleRepository
+	chain       *llm.Chain
+	chunkLimits chunking.Limits
+}
+
+func NewLLMSummarizer(
+	styleRepo repository.StyleRepository,
+	chain *llm.Chain,
+	chunkLimits chunking.Limits,
+) *LLMSummarizer {
+	return &LLMSummarizer{
+		styleRepo:   styleRepo,
+		chain:       chain,
+		chunkLimits: chunkLimits,
+	}
+}
+
+func (s *LLMSummarizer) Summarize(ctx context.Context, styleName string, data prompt.Data) (*Summary, error) {
+	return s.summarize(ctx, styleName, data, nil)
+}
+
+func (s *LLMSummarizer) SummarizeStream(ctx context.Context, styleName string, data prompt.Data, onDelta func(string) error) (*Summary, error) {
+	return s.summarize(ctx, styleName, data, onDelta)
+}
+
+func (s *LLMSummarizer) Translate(ctx context.Context, text, language string) (*Summary, error) {
+	completion, err := s.chain.Complete(ctx, []llm.Message{
+		{Role: "user", Content: translatePrompt(text, language)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Summary{
+		Text:     completion.Text,
+		Chunks:   1,
+		Provider: completion.Provider,
+		Model:    completion.Model,
+		Usage:    completion.Usage,
+	}, nil
+}
+
+func (s *LLMSummarizer) PreferredModel() string {
+	return s.chain.PreferredModel()
+}
+
+func (s *LLMSummarizer) summarize(ctx context.Context, styleName string, data prompt.Data, onDelta func(string) error) (*Summary, error) {
+	start := time.Now()
+
+	style, err := s.styleRepo.GetByName(ctx, styleName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get style: %w", err)
+	}
+	if style == nil {
+		return nil, fmt.Errorf("%w: %s", ErrStyleNotFound, styleName)
+	}
+
+	limits := s.chunkLimits.Smallest(s.chain.Models())
+	chunks := chunking.SplitAt(data.Content, data.ChapterOffsets, limits.MaxTokens)
+	var usage llm.Usage
+	if len(chunks) > 1 {
+		reduced, mapUsage, err := s.mapChunks(ctx, chunks, limits)
+		if err != nil {
+			return nil, err
+		}
+		data.Content = reduced
+		usage = mapUsage
+	}
+
+	content, err := prompt.Render(style.PromptTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []llm.Message{{Role: "user", Content: content}}
+
+	var completion *llm.Completion
+	if onDelta == nil {
+		completion, err = s.chain.Complete(ctx, messages)
+	} else {
+		completion, err = s.chain.Stream(ctx, messages, onDelta)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	usage.Add(completion.Usage)
+	log.Printf("Process completed in %s via %s/%s (%d chunks, %d prompt and %d completion tokens)",
+		time.Since(start), completion.Provider, completion.Model, len(chunks), usage.PromptTokens, usage.CompletionTokens)
+	return &Summary{
+		Text:     completion.Text,
+		Chunks:   len(chunks),
+		Provider: completion.Provider,
+		Model:    completion.Model,
+		Usage:    usage,
+	}, nil
+}
+
+func (s *LLMSummarizer) mapChunks(ctx context.Context, chunks []string, limits chunking.Config) (string, llm.Usage, error) {
+	var usage llm.Usage
+	for len(chunks) > 1 {
+		summaries, chunkUsage, err := s.summarizeChunks(ctx, chunks, limits.Concurrency)
+		if err != nil {
+			return "", usage, err
+		}
+		usage.Add(chunkUsage)
+
+		var combined strings.Builder
+		for i, summary := range summaries {
+			if i > 0 {
+				combined.WriteString("\n\n")
+			}
+			fmt.Fprintf(&combined, "Part %d of %d:\n%s", i+1, len(summaries), summary)
+		}
+
+		next := chunking.Split(combined.String(), limits.MaxTokens)
+		if len(next) == 1 || len(next) >= len(chunks) {
+			return combined.String(), usage, nil
+		}
+		chunks = next
+	}
+	return chunks[0], usage, nil
+}
+
+func (s *LLMSummarizer) summarizeChunks(ctx context.Context, chunks []string, concurrency int) ([]string, llm.Usage, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	summaries := make([]string, len(chunks))
+	usages := make([]llm.Usage, len(chunks))
+	errs := make(chan error, len(chunks))
+	sem := make(chan struct{}, concurrency)
+
+	for i, chunk := range chunks {
+		go func(i int, chunk string) {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			completion, err := s.chain.Complete(ctx, []llm.Message{
+				{Role: "user", Content: chunkPrompt(chunk, i, len(chunks))},
+			})
+			if err != nil {
+				cancel()
+				errs <- fmt.Errorf("failed to summarize chunk %d of %d: %w", i+1, len(chunks), err)
+				return
+			}
+			summaries[i] = completion.Text
+			usages[i] = completion.Usage
+			errs <- nil
+		}(i, chunk)
+	}
+
+	var firstErr error
+	for range chunks {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return nil, llm.Usage{}, firstErr
+	}
+
+	var usage llm.Usage
+	for _, u := range usages {
+		usage.Add(u)
+	}
+	return summaries, usage, nil
+}
+
+func chunkPrompt(chunk string, index, total int) string {
+	return fmt.Sprintf(
+		"The following text is part %d of %d of a longer document. "+
+			"Summarize it thoroughly, keeping every key fact, figure, name, date and argument, "+
+			"so the part summaries can later be combined into one summary of the whole document.\n\n%s",
+		index+1, total, chunk,
+	)
+}
+
+func translatePrompt(text, language string) string {
+	return fmt.Sprintf(
+		"Translate the following summary into %s. Keep its meaning, formatting, names and figures, "+
+			"and reply with the translation only.\n\n%s",
+		language, text,
+	)
+}
@@ -1,145 +0,0 @@
-package openrouter
-
-import (
-	"bytes"
-	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"log"
-	"net/http"
-	"os"
-	"time"
-
-	"anpurnama/summarizer-backend/internal/repository"
-
-	"github.com/joho/godotenv"
-)
-
-type Client struct {
-	httpClient      *http.Client
-	apiKey          string
-	baseURL         string
-	model           string
-	styleRepository repository.StyleRepository
-}
-
-type OpenRouterRequest struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
-}
-
-type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
-
-type OpenRouterResponse struct {
-	Choices []Choice `json:"choices"`
-	Error   *Error   `json:"error,omitempty"`
-}
-
-type Choice struct {
-	Message Message `json:"message"`
-}
-
-type Error struct {
-	Message string `json:"message"`
-	Type    string `json:"type"`
-}
-
-func NewClient(styleRepo repository.StyleRepository) (*Client, error) {
-	if err := godotenv.Load(); err != nil {
-		return nil, fmt.Errorf("error loading .env file: %w", err)
-	}
-
-	apiKey := os.Getenv("OPENROUTER_API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("OPENROUTER_API_KEY environment variable is required")
-	}
-
-	model := os.Getenv("OPENROUTER_MODEL")
-	if model == "" {
-		model = "openai/gpt-4.1-nano" // default fallback
-	}
-
-	return &Client{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		apiKey:          apiKey,
-		baseURL:         "https://openrouter.ai/api/v1",
-		model:           model,
-		styleRepository: styleRepo,
-	}, nil
-}
-
-func (c *Client) Summarize(ctx context.Context, content string, styleName string) (string, error) {
-	start := time.Now()
-
-	style, err := c.styleRepository.GetByName(ctx, styleName)
-	if err != nil {
-		return "", fmt.Errorf("failed to get style: %w", err)
-	}
-	if style == nil {
-		return "", fmt.Errorf("style '%s' not found", styleName)
-	}
-
-	prompt := fmt.Sprintf("%s\n\n%s", style.PromptTemplate, content)
-
-	request := OpenRouterRequest{
-		Model: c.model,
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-	}
-
-	jsonData, err := json.Marshal(request)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to make request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	var openRouterResp OpenRouterResponse
-	if err := json.Unmarshal(body, &openRouterResp); err != nil {
-		return "", fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	if openRouterResp.Error != nil {
-		return "", fmt.Errorf("OpenRouter API error: %s", openRouterResp.Error.Message)
-	}
-
-	if len(openRouterResp.Choices) == 0 {
-		return "", fmt.Errorf("no response received from OpenRouter")
-	}
-
-	summary := openRouterResp.Choices[0].Message.Content
-
-	log.Printf("Process completed in %s", time.Since(start))
-	return summary, nil
-}
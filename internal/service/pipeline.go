@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	"anpurnama/summarizer-backend/internal/repository"
+	"anpurnama/summarizer-backend/internal/service/extractor"
+	"anpurnama/summarizer-backend/internal/service/prompt"
+)
+
+const (
+	DefaultStyle = "concise"
+	indexTimeout = time.Minute
+)
+
+type Stage string
+
+const (
+	StageExtracting  Stage = "extracting"
+	StageSummarizing Stage = "summarizing"
+	StageSaving      Stage = "saving"
+)
+
+var (
+	ErrStyleNotFound        = errors.New("style not found")
+	ErrStreamingUnsupported = errors.New("streaming is not supported by the summarizer")
+	ErrHistoryNotFound      = errors.New("history not found")
+	ErrUnsupportedLanguage  = errors.New("unsupported language")
+)
+
+type StageError struct {
+	Stage Stage
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s failed: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+type SummarizeInput struct {
+	URL string
+	// Document, when set, is summarized instead of fetching URL.
+	Document *extractor.Document
+	Style    string
+	Options  SummarizeOptions
+	// APIKey is a fingerprint of the caller's key that usage is attributed to.
+	APIKey string
+}
+
+// Source identifies what was summarized: the URL, or for text and uploaded files a
+// synthetic "text:<hash>" or "file:<hash>/<name>" identifier derived from the bytes.
+func (in SummarizeInput) Source() string {
+	if in.Document == nil {
+		return in.URL
+	}
+
+	sum := sha256.Sum256(in.Document.Data)
+	hash := hex.EncodeToString(sum[:8])
+	if in.Document.Name == "" {
+		return "text:" + hash
+	}
+	return "file:" + hash + "/" + url.PathEscape(path.Base(in.Document.Name))
+}
+
+type SummarizeOptions struct {
+	MaxWords int               `json:"max_words,omitempty"`
+	Params   map[string]string `json:"params,omitempty"`
+	Refresh  bool              `json:"refresh,omitempty"`
+	// TargetLanguage is the ISO 639-1 code to write the summary in, overriding the
+	// style's default language. Without either the summary follows the source.
+	TargetLanguage string `json:"target_language,omitempty"`
+}
+
+type SummarizeResult struct {
+	History   *repository.History
+	Extracted *extractor.ExtractedContent
+	Cached    bool
+}
+
+type Progress struct {
+	OnStage     func(stage Stage)
+	OnExtracted func(content *extractor.ExtractedContent)
+	OnDelta     func(delta string) error
+}
+
+// HistoryIndexer is notified of every saved history row, e.g. to embed it for semantic search.
+type HistoryIndexer interface {
+	Index(ctx context.Context, history *repository.History) error
+}
+
+type Pipeline struct {
+	historyRepo repository.HistoryRepository
+	styleRepo   repository.StyleRepository
+	extractor   extractor.ContentExtractor
+	summarizer  Summarizer
+	indexer     HistoryIndexer
+
+	flightsMu sync.Mutex
+	flights   map[string]*flight
+}
+
+func NewPipeline(
+	historyRepo repository.HistoryRepository,
+	styleRepo repository.StyleRepository,
+	extractor extractor.ContentExtractor,
+	summarizer Summarizer,
+	indexer HistoryIndexer,
+) *Pipeline {
+	return &Pipeline{
+		historyRepo: historyRepo,
+		styleRepo:   styleRepo,
+		extractor:   extractor,
+		summarizer:  summarizer,
+		indexer:     indexer,
+		flights:     make(map[string]*flight),
+	}
+}
+
+func (p *Pipeline) SupportsStreaming() bool {
+	_, ok := p.summarizer.(StreamSummarizer)
+	return ok
+}
+
+func (p *Pipeline) run(ctx context.Context, input SummarizeInput, progress Progress) (*SummarizeResult, error) {
+	styleName := input.Style
+	if styleName == "" {
+		styleName = DefaultStyle
+	}
+
+	style, err := p.styleRepo.GetByName(ctx, styleName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get style: %w", err)
+	}
+	if style == nil {
+		return nil, fmt.Errorf("%w: %s", ErrStyleNotFound, styleName)
+	}
+	options := input.Options
+	if options.TargetLanguage == "" && style.DefaultLanguage != nil {
+		options.TargetLanguage = *style.DefaultLanguage
+	}
+
+	progress.stage(StageExtracting)
+	var extracted *extractor.ExtractedContent
+	if input.Document != nil {
+		extracted, err = p.extractor.ExtractDocument(ctx, *input.Document)
+	} else {
+		extracted, err = p.extractor.Extract(ctx, input.URL)
+	}
+	if err != nil {
+		return nil, &StageError{Stage: StageExtracting, Err: err}
+	}
+	progress.extracted(extracted)
+
+	progress.stage(StageSummarizing)
+	cacheKey := repository.SummaryCacheKey{
+		ContentHash:   contentHash(extracted.ModelText()),
+		StyleID:       style.ID,
+		PromptVersion: promptVersion(style, options),
+		Model:         p.summarizer.PreferredModel(),
+	}
+	if !options.Refresh {
+		cached, err := p.cachedResult(ctx, cacheKey, extracted, progress)
+		if err != nil {
+			return nil, &StageError{Stage: StageSummarizing, Err: err}
+		}
+		if cached != nil && cached.History.URL == input.Source() {
+			return cached, nil
+		}
+		if cached != nil {
+			// The same content reached through another source still gets a row of
+			// its own, reusing the summary without counting its cost again.
+			progress.stage(StageSaving)
+			history := newHistory(input, extracted, style, options, cacheKey, &Summary{
+				Text:     cached.History.Summary,
+				Chunks:   cached.History.ChunkCount,
+				Provider: stringValue(cached.History.Provider),
+				Model:    stringValue(cached.History.Model),
+			})
+			if err := p.historyRepo.Create(ctx, history); err != nil {
+				return nil, &StageError{Stage: StageSaving, Err: err}
+			}
+			p.index(ctx, *history)
+			return &SummarizeResult{History: history, Extracted: extracted, Cached: true}, nil
+		}
+	}
+
+	summary, err := p.summarize(ctx, styleName, prompt.Data{
+		Title:          extracted.Title,
+		SiteName:       extracted.SiteName,
+		Author:         extracted.Author,
+		PublishDate:    extracted.PublishDate,
+		Language:       extractor.LanguageName(extracted.Language),
+		TargetLanguage: extractor.LanguageName(options.TargetLanguage),
+		Content:        extracted.ModelText(),
+		ChapterOffsets: extracted.ChapterOffsets(),
+		MaxWords:       options.MaxWords,
+		Params:         options.Params,
+	}, progress.OnDelta)
+	if err != nil {
+		return nil, &StageError{Stage: StageSummarizing, Err: err}
+	}
+
+	progress.stage(StageSaving)
+	// The row is keyed by the model that actually ran, which after a fallback is
+	// not the preferred one it was looked up with.
+	cacheKey.Model = summary.Model
+	history := newHistory(input, extracted, style, options, cacheKey, summary)
+	if err := p.historyRepo.Create(ctx, history); err != nil {
+		return nil, &StageError{Stage: StageSaving, Err: err}
+	}
+	p.index(ctx, *history)
+
+	return &SummarizeResult{History: history, Extracted: extracted}, nil
+}
+
+func newHistory(
+	input SummarizeInput,
+	extracted *extractor.ExtractedContent,
+	style *repository.Style,
+	options SummarizeOptions,
+	key repository.SummaryCacheKey,
+	summary *Summary,
+) *repository.History {
+	history := &repository.History{
+		URL:             input.Source(),
+		Title:           nonEmpty(extracted.Title),
+		Content:         extracted.Content,
+		Summary:         summary.Text,
+		StyleID:         &style.ID,
+		Language:        nonEmpty(extracted.Language),
+		SummaryLanguage: nonEmpty(options.TargetLanguage),
+		ChunkCount:      summary.Chunks,
+		Provider:        nonEmpty(summary.Provider),
+		Model:           nonEmpty(key.Model),
+		Cost:            summary.Usage.Cost,
+		ContentHash:     &key.ContentHash,
+		PromptVersion:   &key.PromptVersion,
+		SiteName:        nonEmpty(extracted.SiteName),
+		Author:          nonEmpty(extracted.Author),
+		Excerpt:         nonEmpty(extracted.Excerpt),
+		ImageURL:        httpURL(extracted.ImageURL),
+	}
+	if history.SummaryLanguage == nil {
+		history.SummaryLanguage = history.Language
+	}
+	if published, err := time.Parse(time.RFC3339, extracted.PublishDate); err == nil {
+		history.PublishDate = &published
+	}
+	if summary.Usage.PromptTokens > 0 || summary.Usage.CompletionTokens > 0 {
+		history.PromptTokens = &summary.Usage.PromptTokens
+		history.CompletionTokens = &summary.Usage.CompletionTokens
+	}
+	if input.APIKey != "" {
+		history.APIKey = &input.APIKey
+	}
+	return history
+}
+
+// index runs in the background so that a slow or failing indexer never delays or
+// fails a summary that has already been saved.
+func (p *Pipeline) index(ctx context.Context, history repository.History) {
+	if p.indexer == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), indexTimeout)
+		defer cancel()
+
+		if err := p.indexer.Index(ctx, &history); err != nil {
+			log.Printf("Failed to index history %d: %v", history.ID, err)
+		}
+	}()
+}
+
+func (p *Pipeline) cachedResult(ctx context.Context, key repository.SummaryCacheKey, extracted *extractor.ExtractedContent, progress Progress) (*SummarizeResult, error) {
+	history, err := p.historyRepo.FindCached(ctx, key)
+	if err != nil {
+		log.Printf("Failed to look up cached summary: %v", err)
+		return nil, nil
+	}
+	if history == nil {
+		return nil, nil
+	}
+
+	if progress.OnDelta != nil {
+		if err := progress.OnDelta(history.Summary); err != nil {
+			return nil, err
+		}
+	}
+	return &SummarizeResult{History: history, Extracted: extracted, Cached: true}, nil
+}
+
+func (p *Pipeline) summarize(ctx context.Context, styleName string, data prompt.Data, onDelta func(string) error) (*Summary, error) {
+	if onDelta == nil {
+		return p.summarizer.Summarize(ctx, styleName, data)
+	}
+
+	streamer, ok := p.summarizer.(StreamSummarizer)
+	if !ok {
+		return nil, ErrStreamingUnsupported
+	}
+	return streamer.SummarizeStream(ctx, styleName, data, onDelta)
+}
+
+func (p Progress) stage(stage Stage) {
+	if p.OnStage != nil {
+		p.OnStage(stage)
+	}
+}
+
+func (p Progress) extracted(content *extractor.ExtractedContent) {
+	if p.OnExtracted != nil {
+		p.OnExtracted(content)
+	}
+}
+
+func (p Progress) delta(delta string) {
+	if p.OnDelta != nil {
+		p.OnDelta(delta)
+	}
+}
+
+// httpURL drops metadata links that would fail validation, so a malformed
+// og:image never costs a summary that has already been paid for.
+func httpURL(s string) *string {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil
+	}
+	return &s
+}
+
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
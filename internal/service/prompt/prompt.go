@@ -0,0 +1,158 @@
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"text/template/parse"
+)
+
+// Data is the model available to style prompt templates, e.g.
+// "Summarize {{.Title}} from {{.SiteName}} in {{.MaxWords}} words: {{.Content}}".
+// Templates that never reference {{.Content}} get the content appended, and those
+// that never reference {{.TargetLanguage}} get an instruction to answer in it.
+type Data struct {
+	Title          string
+	SiteName       string
+	Author         string
+	PublishDate    string
+	Language       string
+	TargetLanguage string
+	Content        string
+	// ChapterOffsets are where chapters start in Content; long content is split
+	// into chunks at them where it can be.
+	ChapterOffsets []int
+	MaxWords       int
+	Params         map[string]string
+}
+
+func Parse(text string) (*template.Template, error) {
+	return template.New("prompt").Option("missingkey=zero").Parse(text)
+}
+
+func Validate(text string) error {
+	tmpl, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(&bytes.Buffer{}, Data{})
+}
+
+func Render(text string, data Data) (string, error) {
+	tmpl, err := Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+
+	if !referencesField(tmpl, "Content") {
+		buf.WriteString("\n\n")
+		buf.WriteString(data.Content)
+	}
+
+	if data.TargetLanguage != "" && !referencesField(tmpl, "TargetLanguage") {
+		fmt.Fprintf(&buf, "\n\nWrite your answer in %s, even if the text is in another language.", data.TargetLanguage)
+	}
+
+	return buf.String(), nil
+}
+
+// referencesField reports whether the template uses a top-level field of Data,
+// following {{template}} and {{block}} calls that pass the same data along.
+// Fields read while {{with}} or {{range}} has moved dot elsewhere do not count.
+func referencesField(tmpl *template.Template, field string) bool {
+	f := fieldFinder{tmpl: tmpl, field: field, visited: make(map[string]bool)}
+	return f.find(tmpl.Tree.Root, true)
+}
+
+type fieldFinder struct {
+	tmpl    *template.Template
+	field   string
+	visited map[string]bool
+}
+
+// find walks node, where dotIsRoot says whether dot still holds the root Data.
+func (f *fieldFinder) find(node parse.Node, dotIsRoot bool) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, child := range n.Nodes {
+			if f.find(child, dotIsRoot) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return f.find(n.Pipe, dotIsRoot)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			if f.find(cmd, dotIsRoot) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if f.find(arg, dotIsRoot) {
+				return true
+			}
+		}
+	case *parse.FieldNode:
+		return dotIsRoot && len(n.Ident) > 0 && n.Ident[0] == f.field
+	case *parse.VariableNode:
+		return len(n.Ident) > 1 && n.Ident[0] == "$" && n.Ident[1] == f.field
+	case *parse.ChainNode:
+		if len(n.Field) > 0 && n.Field[0] == f.field {
+			switch inner := n.Node.(type) {
+			case *parse.DotNode:
+				if dotIsRoot {
+					return true
+				}
+			case *parse.PipeNode:
+				if passesRoot(inner, dotIsRoot) {
+					return true
+				}
+			}
+		}
+		return f.find(n.Node, dotIsRoot)
+	case *parse.IfNode:
+		return f.find(n.Pipe, dotIsRoot) || f.find(n.List, dotIsRoot) || f.find(n.ElseList, dotIsRoot)
+	case *parse.RangeNode:
+		return f.find(n.Pipe, dotIsRoot) || f.find(n.List, false) || f.find(n.ElseList, dotIsRoot)
+	case *parse.WithNode:
+		return f.find(n.Pipe, dotIsRoot) || f.find(n.List, passesRoot(n.Pipe, dotIsRoot)) || f.find(n.ElseList, dotIsRoot)
+	case *parse.TemplateNode:
+		if f.find(n.Pipe, dotIsRoot) {
+			return true
+		}
+		if !passesRoot(n.Pipe, dotIsRoot) || f.visited[n.Name] {
+			return false
+		}
+		f.visited[n.Name] = true
+		called := f.tmpl.Lookup(n.Name)
+		return called != nil && called.Tree != nil && f.find(called.Tree.Root, true)
+	}
+	return false
+}
+
+// passesRoot reports whether pipe evaluates to the root data, so that fields
+// read from its result, such as inside a {{template}} call, refer to Data.
+func passesRoot(pipe *parse.PipeNode, dotIsRoot bool) bool {
+	if pipe == nil || len(pipe.Decl) > 0 || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.DotNode:
+		return dotIsRoot
+	case *parse.VariableNode:
+		return len(arg.Ident) == 1 && arg.Ident[0] == "$"
+	}
+	return false
+}
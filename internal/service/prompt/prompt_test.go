@@ -0,0 +1,128 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr bool
+	}{
+		{"fields", "Summarize {{.Title}} in {{.MaxWords}} words: {{.Content}}", false},
+		{"params", `Tone: {{index .Params "tone"}} {{.Params.audience}}`, false},
+		{"no fields", "Summarize this.", false},
+		{"unclosed action", "Summarize {{.Title", true},
+		{"unclosed block", "{{if .Title}}Summarize", true},
+		{"unknown field", "Summarize {{.Body}}", true},
+		{"unknown function", "{{shout .Title}}", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.text); (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) = %v, want error %v", tt.text, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReferencesField(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{"field", "Text: {{.Content}}", true},
+		{"root variable", "{{range .ChapterOffsets}}{{$.Content}}{{end}}", true},
+		{"pipeline", "{{.Content | printf \"%.100s\"}}", true},
+		{"function argument", "{{printf \"%s\" .Content}}", true},
+		{"if", "{{if .Content}}has text{{end}}", true},
+		{"with on the field", "{{with .Content}}{{.}}{{end}}", true},
+		{"with else keeps dot", "{{with .Params.x}}{{.}}{{else}}{{.Content}}{{end}}", true},
+		{"with on dot", "{{with .}}{{.Content}}{{end}}", true},
+		{"chain on dot", "{{(.).Content}}", true},
+		{"template passing dot", `{{define "body"}}{{.Content}}{{end}}{{template "body" .}}`, true},
+		{"template passing root", `{{define "body"}}{{.Content}}{{end}}{{with .Params}}{{template "body" $}}{{end}}`, true},
+		{"block", `{{block "body" .}}{{.Content}}{{end}}`, true},
+		{"recursive template", `{{define "loop"}}{{template "loop" .}}{{end}}{{template "loop" .}}`, false},
+		{"absent", "Summarize {{.Title}}.", false},
+		{"other field with prefix", "{{.ContentType}}", false},
+		{"params key", "{{.Params.Content}}", false},
+		{"with moves dot", "{{with .Params}}{{.Content}}{{end}}", false},
+		{"range moves dot", "{{range .ChapterOffsets}}{{.Content}}{{end}}", false},
+		{"template not passing dot", `{{define "body"}}{{.Content}}{{end}}{{template "body" .Params}}`, false},
+		{"template inside with", `{{define "body"}}{{.Content}}{{end}}{{with .Params}}{{template "body" .}}{{end}}`, false},
+		{"defined but not called", `{{define "body"}}{{.Content}}{{end}}Summarize.`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := Parse(tt.text)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.text, err)
+			}
+			if got := referencesField(tmpl, "Content"); got != tt.want {
+				t.Errorf("referencesField(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderAppendsMissingContent(t *testing.T) {
+	data := Data{Title: "Report", Content: "THE CONTENT", Params: map[string]string{"Content": "param"}}
+
+	got, err := Render("Summarize {{.Title}}: {{.Content}}", data)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if got != "Summarize Report: THE CONTENT" {
+		t.Errorf("Render = %q, want the content once", got)
+	}
+
+	got, err = Render("Summarize {{.Title}}.", data)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if got != "Summarize Report.\n\nTHE CONTENT" {
+		t.Errorf("Render = %q, want the content appended", got)
+	}
+
+	got, err = Render("{{with .Params}}Note: {{.Content}}{{end}}", data)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if got != "Note: param\n\nTHE CONTENT" {
+		t.Errorf("Render = %q, want the content appended after a param of the same name", got)
+	}
+}
+
+func TestRenderTargetLanguage(t *testing.T) {
+	const instruction = "Write your answer in"
+	tests := []struct {
+		name     string
+		text     string
+		language string
+		want     bool
+	}{
+		{"unset", "Summarize: {{.Content}}", "", false},
+		{"not referenced", "Summarize: {{.Content}}", "French", true},
+		{"referenced", "Summarize in {{.TargetLanguage}}: {{.Content}}", "French", false},
+		{"referenced conditionally", "{{if .TargetLanguage}}Answer in {{.TargetLanguage}}.{{end}} {{.Content}}", "French", false},
+		{"referenced through a template", `{{define "lang"}}in {{.TargetLanguage}}{{end}}Summarize {{template "lang" .}}: {{.Content}}`, "French", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.text, Data{Content: "text", TargetLanguage: tt.language})
+			if err != nil {
+				t.Fatalf("Render returned error: %v", err)
+			}
+			if appended := strings.Contains(got, instruction); appended != tt.want {
+				t.Errorf("Render = %q, want the language instruction appended: %v", got, tt.want)
+			}
+			if tt.language != "" && !strings.Contains(got, tt.language) {
+				t.Errorf("Render = %q, want it to mention %s", got, tt.language)
+			}
+		})
+	}
+}
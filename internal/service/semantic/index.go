@@ -0,0 +1,176 @@
+package semantic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"anpurnama/summarizer-backend/internal/repository"
+	"anpurnama/summarizer-backend/internal/service/embedding"
+)
+
+// maxContentRunes bounds the article text sent to the embedder; embedding models
+// have small context windows and the opening of an article carries most of its topic.
+const maxContentRunes = 8000
+
+var ErrEmptyQuery = errors.New("search query is empty")
+
+type Match struct {
+	History repository.History
+	Score   float64
+}
+
+type Index struct {
+	historyRepo   repository.HistoryRepository
+	embeddingRepo repository.EmbeddingRepository
+	embedder      embedding.Embedder
+}
+
+func NewIndex(
+	historyRepo repository.HistoryRepository,
+	embeddingRepo repository.EmbeddingRepository,
+	embedder embedding.Embedder,
+) *Index {
+	return &Index{
+		historyRepo:   historyRepo,
+		embeddingRepo: embeddingRepo,
+		embedder:      embedder,
+	}
+}
+
+func (i *Index) Index(ctx context.Context, history *repository.History) error {
+	return i.indexBatch(ctx, []repository.History{*history})
+}
+
+func (i *Index) Search(ctx context.Context, query string, limit int) ([]Match, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrEmptyQuery
+	}
+
+	vectors, err := i.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	embeddings, err := i.embeddingRepo.ListByModel(ctx, i.embedder.Model())
+	if err != nil {
+		return nil, err
+	}
+
+	// A history matches as well as the best of its summary and content vectors.
+	scores := make(map[int]float64)
+	for _, e := range embeddings {
+		score := embedding.Cosine(vectors[0], e.Vector)
+		if best, ok := scores[e.HistoryID]; !ok || score > best {
+			scores[e.HistoryID] = score
+		}
+	}
+
+	ranked := make([]Match, 0, len(scores))
+	for id, score := range scores {
+		ranked = append(ranked, Match{History: repository.History{ID: id}, Score: score})
+	}
+	sort.Slice(ranked, func(a, b int) bool {
+		if ranked[a].Score != ranked[b].Score {
+			return ranked[a].Score > ranked[b].Score
+		}
+		return ranked[a].History.ID > ranked[b].History.ID
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	ids := make([]int, len(ranked))
+	for n, match := range ranked {
+		ids[n] = match.History.ID
+	}
+	histories, err := i.historyRepo.ListWithStylesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]repository.History, len(histories))
+	for _, h := range histories {
+		byID[h.ID] = h
+	}
+
+	matches := make([]Match, 0, len(ranked))
+	for _, match := range ranked {
+		if history, ok := byID[match.History.ID]; ok {
+			matches = append(matches, Match{History: history, Score: match.Score})
+		}
+	}
+	return matches, nil
+}
+
+// Backfill embeds every history row that has no vectors for the current model yet
+// and returns how many rows were embedded.
+func (i *Index) Backfill(ctx context.Context, batchSize int, onBatch func(done int)) (int, error) {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	done := 0
+	lastID := 0
+	for {
+		histories, err := i.embeddingRepo.ListUnembedded(ctx, i.embedder.Model(), lastID, batchSize)
+		if err != nil {
+			return done, err
+		}
+		if len(histories) == 0 {
+			return done, nil
+		}
+
+		if err := i.indexBatch(ctx, histories); err != nil {
+			return done, err
+		}
+		done += len(histories)
+		lastID = histories[len(histories)-1].ID
+		if onBatch != nil {
+			onBatch(done)
+		}
+	}
+}
+
+func (i *Index) indexBatch(ctx context.Context, histories []repository.History) error {
+	texts := make([]string, 0, 2*len(histories))
+	for _, h := range histories {
+		texts = append(texts, summaryText(h), contentText(h))
+	}
+
+	vectors, err := i.embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed history: %w", err)
+	}
+
+	model := i.embedder.Model()
+	embeddings := make([]repository.Embedding, 0, len(texts))
+	for n, h := range histories {
+		embeddings = append(embeddings,
+			repository.Embedding{HistoryID: h.ID, Kind: repository.EmbeddingKindSummary, Model: model, Vector: vectors[2*n]},
+			repository.Embedding{HistoryID: h.ID, Kind: repository.EmbeddingKindContent, Model: model, Vector: vectors[2*n+1]},
+		)
+	}
+	return i.embeddingRepo.Upsert(ctx, embeddings)
+}
+
+func summaryText(h repository.History) string {
+	return withTitle(h, h.Summary)
+}
+
+func contentText(h repository.History) string {
+	content := []rune(h.Content)
+	if len(content) > maxContentRunes {
+		content = content[:maxContentRunes]
+	}
+	return withTitle(h, string(content))
+}
+
+func withTitle(h repository.History, text string) string {
+	if h.Title == nil || *h.Title == "" {
+		return text
+	}
+	return *h.Title + "\n\n" + text
+}
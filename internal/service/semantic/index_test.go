@@ -0,0 +1,299 @@
+package semantic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"anpurnama/summarizer-backend/internal/repository"
+	"anpurnama/summarizer-backend/internal/service/embedding"
+)
+
+// memoryStore stands in for both repositories the index reads and writes.
+type memoryStore struct {
+	repository.HistoryRepository
+	histories  map[int]repository.History
+	embeddings map[string]repository.Embedding
+	lookups    int
+}
+
+func newMemoryStore(histories ...repository.History) *memoryStore {
+	store := &memoryStore{
+		histories:  make(map[int]repository.History),
+		embeddings: make(map[string]repository.Embedding),
+	}
+	for _, h := range histories {
+		store.histories[h.ID] = h
+	}
+	return store
+}
+
+func embeddingKey(historyID int, kind, model string) string {
+	return fmt.Sprintf("%d/%s/%s", historyID, kind, model)
+}
+
+func (s *memoryStore) ListWithStylesByIDs(_ context.Context, ids []int) ([]repository.History, error) {
+	s.lookups++
+	var histories []repository.History
+	for _, id := range ids {
+		if h, ok := s.histories[id]; ok {
+			histories = append(histories, h)
+		}
+	}
+	return histories, nil
+}
+
+func (s *memoryStore) Upsert(_ context.Context, embeddings []repository.Embedding) error {
+	for _, e := range embeddings {
+		s.embeddings[embeddingKey(e.HistoryID, e.Kind, e.Model)] = e
+	}
+	return nil
+}
+
+func (s *memoryStore) ListByModel(_ context.Context, model string) ([]repository.Embedding, error) {
+	var result []repository.Embedding
+	for _, e := range s.embeddings {
+		if e.Model == model {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (s *memoryStore) ListUnembedded(_ context.Context, model string, afterID, limit int) ([]repository.History, error) {
+	var ids []int
+	for id := range s.histories {
+		_, summary := s.embeddings[embeddingKey(id, repository.EmbeddingKindSummary, model)]
+		_, content := s.embeddings[embeddingKey(id, repository.EmbeddingKindContent, model)]
+		if id > afterID && !(summary && content) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	var result []repository.History
+	for _, id := range ids[:min(limit, len(ids))] {
+		result = append(result, s.histories[id])
+	}
+	return result, nil
+}
+
+func (s *memoryStore) vector(t *testing.T, historyID int, kind, model string) []float32 {
+	t.Helper()
+	e, ok := s.embeddings[embeddingKey(historyID, kind, model)]
+	if !ok {
+		t.Fatalf("no %s embedding for history %d", kind, historyID)
+	}
+	return e.Vector
+}
+
+func history(id int, title, summary, content string) repository.History {
+	return repository.History{ID: id, Title: &title, Summary: summary, Content: content}
+}
+
+var articles = []repository.History{
+	history(1, "Sourdough basics", "How to keep a sourdough starter alive.", "Feed the starter flour and water daily and keep it warm so the yeast stays active."),
+	history(2, "Tuning Go services", "Profiling and tuning Go services.", "Use pprof to profile goroutines, heap allocations and garbage collector pauses."),
+	history(3, "Alpine hiking", "Planning a multi-day hike in the Alps.", "Book mountain huts early, pack layers and check the glacier conditions before each stage."),
+}
+
+func TestIndexBatchPairsVectorsWithTheirHistory(t *testing.T) {
+	store := newMemoryStore()
+	embedder := embedding.NewHashing(64)
+	index := NewIndex(store, store, embedder)
+
+	if err := index.indexBatch(context.Background(), articles); err != nil {
+		t.Fatalf("indexBatch returned error: %v", err)
+	}
+	if len(store.embeddings) != 2*len(articles) {
+		t.Fatalf("stored %d embeddings, want a summary and a content vector per history", len(store.embeddings))
+	}
+
+	for _, h := range articles {
+		want, _ := embedder.Embed(context.Background(), []string{summaryText(h), contentText(h)})
+		summary := store.vector(t, h.ID, repository.EmbeddingKindSummary, embedder.Model())
+		content := store.vector(t, h.ID, repository.EmbeddingKindContent, embedder.Model())
+		if !slices.Equal(summary, want[0]) {
+			t.Errorf("history %d: summary vector does not embed its own summary", h.ID)
+		}
+		if !slices.Equal(content, want[1]) {
+			t.Errorf("history %d: content vector does not embed its own content", h.ID)
+		}
+	}
+}
+
+func TestContentTextIsBounded(t *testing.T) {
+	h := history(1, "Long", "", strings.Repeat("é", maxContentRunes+500))
+	text := contentText(h)
+	if got := len([]rune(text)); got != len([]rune("Long\n\n"))+maxContentRunes {
+		t.Errorf("content text has %d runes, want the title and %d content runes", got, maxContentRunes)
+	}
+
+	untitled := repository.History{ID: 2, Summary: "Only a summary."}
+	if summaryText(untitled) != "Only a summary." {
+		t.Errorf("summaryText = %q, want the summary alone without a title", summaryText(untitled))
+	}
+}
+
+func TestSearchRanksClosestHistoryFirst(t *testing.T) {
+	store := newMemoryStore(articles...)
+	index := NewIndex(store, store, embedding.NewHashing(256))
+	if err := index.indexBatch(context.Background(), articles); err != nil {
+		t.Fatalf("indexBatch returned error: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"keeping a sourdough starter alive", 1},
+		{"profile goroutines with pprof", 2},
+		{"mountain huts in the Alps", 3},
+	}
+	for _, tt := range tests {
+		matches, err := index.Search(context.Background(), tt.query, 3)
+		if err != nil {
+			t.Fatalf("Search(%q) returned error: %v", tt.query, err)
+		}
+		if len(matches) != 3 {
+			t.Fatalf("Search(%q) returned %d matches, want 3", tt.query, len(matches))
+		}
+		if matches[0].History.ID != tt.want {
+			t.Errorf("Search(%q) ranked history %d first, want %d", tt.query, matches[0].History.ID, tt.want)
+		}
+		if matches[0].History.Title == nil || matches[0].History.Summary == "" {
+			t.Errorf("Search(%q) returned a match without its history loaded", tt.query)
+		}
+		for i := 1; i < len(matches); i++ {
+			if matches[i].Score > matches[i-1].Score {
+				t.Errorf("Search(%q) results are not sorted by score: %v", tt.query, matches)
+			}
+		}
+	}
+}
+
+func TestSearchLimitsAndSkipsDeletedHistories(t *testing.T) {
+	store := newMemoryStore(articles...)
+	index := NewIndex(store, store, embedding.NewHashing(256))
+	if err := index.indexBatch(context.Background(), articles); err != nil {
+		t.Fatalf("indexBatch returned error: %v", err)
+	}
+
+	matches, err := index.Search(context.Background(), "sourdough", 1)
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("Search with limit 1 = %v, %v, want one match", matches, err)
+	}
+
+	delete(store.histories, 1)
+	matches, err = index.Search(context.Background(), "sourdough", 3)
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	for _, m := range matches {
+		if m.History.ID == 1 {
+			t.Errorf("Search returned deleted history 1")
+		}
+	}
+	if len(matches) != 2 {
+		t.Errorf("Search returned %d matches, want the 2 remaining histories", len(matches))
+	}
+	if store.lookups != 2 {
+		t.Errorf("two searches loaded histories %d times, want one lookup each", store.lookups)
+	}
+}
+
+func TestSearchOnlyComparesVectorsOfTheSameModel(t *testing.T) {
+	store := newMemoryStore(articles...)
+	if err := NewIndex(store, store, embedding.NewHashing(64)).indexBatch(context.Background(), articles[:1]); err != nil {
+		t.Fatalf("indexBatch returned error: %v", err)
+	}
+
+	index := NewIndex(store, store, embedding.NewHashing(128))
+	if err := index.indexBatch(context.Background(), articles[1:]); err != nil {
+		t.Fatalf("indexBatch returned error: %v", err)
+	}
+
+	matches, err := index.Search(context.Background(), "sourdough starter", 10)
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("Search returned %d matches, want only the 2 histories embedded with this model", len(matches))
+	}
+	for _, m := range matches {
+		if m.History.ID == 1 {
+			t.Errorf("Search matched history 1, which has no vectors for this model")
+		}
+	}
+}
+
+func TestSearchRejectsEmptyQuery(t *testing.T) {
+	store := newMemoryStore()
+	index := NewIndex(store, store, embedding.NewHashing(64))
+	if _, err := index.Search(context.Background(), "   ", 10); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("err = %v, want ErrEmptyQuery", err)
+	}
+}
+
+func TestBackfillEmbedsOnlyMissingHistories(t *testing.T) {
+	store := newMemoryStore(
+		history(1, "One", "first", "one"),
+		history(2, "Two", "second", "two"),
+		history(3, "Three", "third", "three"),
+		history(4, "Four", "fourth", "four"),
+		history(5, "Five", "fifth", "five"),
+	)
+	embedder := embedding.NewHashing(64)
+	index := NewIndex(store, store, embedder)
+	if err := index.Index(context.Background(), &repository.History{ID: 2, Summary: "second", Content: "two"}); err != nil {
+		t.Fatalf("Index returned error: %v", err)
+	}
+	embedded := len(store.embeddings)
+
+	var progress []int
+	done, err := index.Backfill(context.Background(), 2, func(done int) {
+		progress = append(progress, done)
+	})
+	if err != nil {
+		t.Fatalf("Backfill returned error: %v", err)
+	}
+	if done != 4 {
+		t.Errorf("Backfill embedded %d histories, want the 4 without vectors", done)
+	}
+	if !slices.Equal(progress, []int{2, 4}) {
+		t.Errorf("progress = %v, want one report per batch of 2", progress)
+	}
+	if len(store.embeddings) != embedded+8 {
+		t.Errorf("stored %d embeddings, want %d", len(store.embeddings), embedded+8)
+	}
+
+	done, err = index.Backfill(context.Background(), 2, nil)
+	if err != nil || done != 0 {
+		t.Errorf("second Backfill = %d, %v, want nothing left to embed", done, err)
+	}
+}
+
+type failingEmbedder struct {
+	embedding.Embedder
+}
+
+func (failingEmbedder) Embed(context.Context, []string) ([][]float32, error) {
+	return nil, errors.New("embedder down")
+}
+
+func TestBackfillStopsOnEmbedderError(t *testing.T) {
+	store := newMemoryStore(articles...)
+	index := NewIndex(store, store, failingEmbedder{embedding.NewHashing(64)})
+
+	done, err := index.Backfill(context.Background(), 10, nil)
+	if err == nil || !strings.Contains(err.Error(), "embedder down") {
+		t.Errorf("err = %v, want the embedder failure", err)
+	}
+	if done != 0 || len(store.embeddings) != 0 {
+		t.Errorf("Backfill embedded %d histories and stored %d vectors, want none", done, len(store.embeddings))
+	}
+}
@@ -1,7 +1,27 @@
 package service
 
-import "context"
+import (
+	"context"
+
+	"anpurnama/summarizer-backend/internal/service/llm"
+	"anpurnama/summarizer-backend/internal/service/prompt"
+)
+
+type Summary struct {
+	Text     string
+	Chunks   int
+	Provider string
+	Model    string
+	Usage    llm.Usage
+}
 
 type Summarizer interface {
-	Summarize(ctx context.Context, content string, styleName string) (string, error)
+	Summarize(ctx context.Context, styleName string, data prompt.Data) (*Summary, error)
+	// Translate rewrites a finished summary in the named language.
+	Translate(ctx context.Context, text, language string) (*Summary, error)
+	PreferredModel() string
+}
+
+type StreamSummarizer interface {
+	SummarizeStream(ctx context.Context, styleName string, data prompt.Data, onDelta func(string) error) (*Summary, error)
 }
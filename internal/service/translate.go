@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"anpurnama/summarizer-backend/internal/repository"
+	"anpurnama/summarizer-backend/internal/service/extractor"
+)
+
+// Translate stores a copy of a history entry with its summary rewritten in the
+// given ISO 639-1 language, reusing an earlier translation, or the original
+// itself, when there is one.
+// The copy keeps the source's content and metadata and is linked to the entry
+// it was first translated from.
+func (p *Pipeline) Translate(ctx context.Context, historyID int, language, apiKey string) (*repository.History, error) {
+	name := extractor.LanguageName(language)
+	if name == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedLanguage, language)
+	}
+
+	source, err := p.historyRepo.GetByID(ctx, historyID)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, ErrHistoryNotFound
+	}
+	if source.SummaryLanguage != nil && *source.SummaryLanguage == language {
+		return source, nil
+	}
+
+	originalID := source.ID
+	if source.TranslatedFrom != nil {
+		originalID = *source.TranslatedFrom
+		original, err := p.historyRepo.GetByID(ctx, originalID)
+		if err != nil {
+			return nil, err
+		}
+		if original != nil && original.SummaryLanguage != nil && *original.SummaryLanguage == language {
+			return original, nil
+		}
+	}
+	existing, err := p.historyRepo.FindTranslation(ctx, originalID, language)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	translation, err := p.summarizer.Translate(ctx, source.Summary, name)
+	if err != nil {
+		return nil, &StageError{Stage: StageSummarizing, Err: err}
+	}
+
+	translated := *source
+	translated.ID = 0
+	translated.Summary = translation.Text
+	translated.SummaryLanguage = &language
+	translated.TranslatedFrom = &originalID
+	translated.ChunkCount = translation.Chunks
+	translated.Provider = &translation.Provider
+	translated.Model = &translation.Model
+	translated.Cost = translation.Usage.Cost
+	translated.PromptTokens = nil
+	translated.CompletionTokens = nil
+	if translation.Usage.PromptTokens > 0 || translation.Usage.CompletionTokens > 0 {
+		translated.PromptTokens = &translation.Usage.PromptTokens
+		translated.CompletionTokens = &translation.Usage.CompletionTokens
+	}
+	translated.APIKey = nil
+	if apiKey != "" {
+		translated.APIKey = &apiKey
+	}
+	// A translation must never be served as a cached summary of the source.
+	translated.ContentHash = nil
+	translated.PromptVersion = nil
+
+	if err := p.historyRepo.Create(ctx, &translated); err != nil {
+		return nil, &StageError{Stage: StageSaving, Err: err}
+	}
+	p.index(ctx, translated)
+
+	return &translated, nil
+}